@@ -1,40 +1,120 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
+	"image/png"
 	"os"
 	"path/filepath"
-	"strings"
+	"sync"
 
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/audit"
 	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/report"
 	"github.com/tungsten-fcl/fcl-controller-auditor/internal/repository"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/service"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/settings"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/thumbnail"
 	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/vcs"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // App struct
 type App struct {
-	ctx     context.Context
-	manager *repository.Manager
-	pkg     *utils.ParsedPackage
+	ctx      context.Context
+	session  *service.Session
+	thumbs   *thumbnail.Cache
+	settings *settings.Store
+
+	// mu serializes the bound methods, which Wails calls concurrently, and
+	// the thumbnail handler's reads of the session
+	mu sync.Mutex
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{}
+	dir, err := thumbnail.DefaultDir()
+	if err != nil {
+		dir = filepath.Join(os.TempDir(), "fcl-controller-auditor-thumbnails")
+	}
+	a := &App{session: service.New(), thumbs: thumbnail.New(dir)}
+	a.thumbs.Roots = a.imageRoots
+
+	path, err := settings.DefaultPath()
+	if err != nil {
+		path = filepath.Join(os.TempDir(), "fcl-controller-auditor-settings.json")
+	}
+	if a.settings, err = settings.Open(path); err != nil {
+		println("Error:", err.Error())
+	}
+	a.applySettings(a.settings.Get())
+	return a
+}
+
+// imageRoots returns the directories the thumbnail handler may read: the
+// open repository and the temp dir of the package under review
+func (a *App) imageRoots() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var roots []string
+	if mgr := a.session.Manager(); mgr != nil {
+		roots = append(roots, mgr.RepoRoot)
+	}
+	if pkg := a.session.Package(); pkg != nil {
+		roots = append(roots, pkg.TempDir)
+	}
+	return roots
 }
 
 // startup is called when the app starts. The context is saved
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.ctx = ctx
+	if last := a.settings.Get().LastRepo(); last != "" {
+		if err := a.openRepo(last); err != nil {
+			a.settings.RemoveRecentRepo(last)
+		}
+	}
+}
+
+// shutdown removes the temporary files of the package under review
+func (a *App) shutdown(ctx context.Context) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.session.Close()
+}
+
+// openRepo opens root in the session and remembers it as the most recent repository
+func (a *App) openRepo(root string) error {
+	if _, err := a.session.OpenRepo(root); err != nil {
+		return err
+	}
+	if name := a.settings.Get().Audit.Profile; name != "" {
+		// The saved profile may not exist in this repository
+		a.session.SelectProfile(name)
+	}
+	if err := a.settings.AddRecentRepo(root); err != nil {
+		println("Error:", err.Error())
+	}
+	return nil
+}
+
+// applySettings hands the audit and preview settings to the session
+func (a *App) applySettings(st settings.Settings) {
+	a.session.PreviewWidth = st.Preview.Width
+	a.session.PreviewHeight = st.Preview.Height
+	a.session.SetDisabledRules(st.Audit.DisabledRules)
 }
 
 // SelectRepoRoot opens a directory dialog to select the repository root
 func (a *App) SelectRepoRoot() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
 		Title: "Select FCL Controller Repository Root",
 	})
@@ -45,18 +125,104 @@ func (a *App) SelectRepoRoot() (string, error) {
 		return "", nil
 	}
 
-	mgr, err := repository.NewManager(dir)
-	if err != nil {
-		return "", fmt.Errorf("invalid repository: %v", err)
+	if err := a.openRepo(dir); err != nil {
+		return "", err
 	}
-	a.manager = mgr
 	return dir, nil
 }
 
+// OpenRecentRepo reopens a repository from the recent list
+func (a *App) OpenRecentRepo(root string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.openRepo(root); err != nil {
+		a.settings.RemoveRecentRepo(root)
+		return "", err
+	}
+	return root, nil
+}
+
+// GetRepoRoot returns the root of the open repository, or ""
+func (a *App) GetRepoRoot() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if mgr := a.session.Manager(); mgr != nil {
+		return mgr.RepoRoot
+	}
+	return ""
+}
+
+// GetSettings returns the saved application settings
+func (a *App) GetSettings() settings.Settings {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.settings.Get()
+}
+
+// UpdateSettings saves new application settings and applies them
+func (a *App) UpdateSettings(st settings.Settings) (settings.Settings, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	saved, err := a.settings.Update(func(cur *settings.Settings) {
+		recent, profile := cur.RecentRepos, cur.Audit.Profile
+		*cur = st
+		cur.RecentRepos, cur.Audit.Profile = recent, profile
+	})
+	a.applySettings(saved)
+	return saved, err
+}
+
+// GetProfiles lists the audit profiles defined in the repository's audit-rules.json
+func (a *App) GetProfiles() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session.Profiles()
+}
+
+// GetProfileName returns the selected audit profile; "" means the built-in rules
+func (a *App) GetProfileName() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session.ProfileName()
+}
+
+// SelectProfile switches the audit profile and remembers the choice
+func (a *App) SelectProfile(name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.session.SelectProfile(name); err != nil {
+		return err
+	}
+	_, err := a.settings.Update(func(st *settings.Settings) {
+		st.Audit.Profile = name
+	})
+	return err
+}
+
+// SelectInboxDir opens a directory dialog to choose the folder submissions arrive in
+func (a *App) SelectInboxDir() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:            "Select Inbox Folder",
+		DefaultDirectory: a.settings.Get().InboxDir,
+	})
+	if err != nil || dir == "" {
+		return "", err
+	}
+	_, err = a.settings.Update(func(st *settings.Settings) {
+		st.InboxDir = dir
+	})
+	return dir, err
+}
+
 // SelectZip opens a file dialog to select a controller ZIP package
 func (a *App) SelectZip() (*utils.ParsedPackage, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	file, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "Select Controller ZIP",
+		Title:            "Select Controller ZIP",
+		DefaultDirectory: a.settings.Get().InboxDir,
 		Filters: []runtime.FileFilter{
 			{DisplayName: "ZIP Files (*.zip)", Pattern: "*.zip"},
 		},
@@ -68,131 +234,473 @@ func (a *App) SelectZip() (*utils.ParsedPackage, error) {
 		return nil, nil
 	}
 
-	pkg, err := utils.ParseControllerZip(file)
-	if err != nil {
+	return a.session.OpenZip(file)
+}
+
+// NewFromLayout starts a new package from a bare layout JSON exported by FCL
+func (a *App) NewFromLayout() (*utils.ParsedPackage, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	file, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select Controller Layout",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "JSON Files (*.json)", Pattern: "*.json"},
+		},
+	})
+	if err != nil || file == "" {
 		return nil, err
 	}
 
-	// Check if this is an update
-	if a.manager != nil {
-		for _, entry := range a.manager.Index {
-			if entry.ID == pkg.ControllerID {
-				pkg.IsUpdate = true
-				copyEntry := entry
-				pkg.CurrentIndex = &copyEntry
-				break
-			}
-		}
+	return a.session.OpenLayout(file)
+}
+
+// SelectPackageIcon replaces the current package's icon
+func (a *App) SelectPackageIcon() (*utils.ParsedPackage, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.session.Package() == nil {
+		return nil, service.ErrNoPackage
 	}
+	file, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select Icon",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "PNG Files (*.png)", Pattern: "*.png"},
+		},
+	})
+	if err != nil || file == "" {
+		return a.session.Package(), err
+	}
+	if err := a.session.SetIcon(file); err != nil {
+		return nil, err
+	}
+	return a.session.Package(), nil
+}
 
-	a.pkg = pkg
-	return pkg, nil
+// AddPackageScreenshots appends screenshots to the current package
+func (a *App) AddPackageScreenshots() (*utils.ParsedPackage, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.session.Package() == nil {
+		return nil, service.ErrNoPackage
+	}
+	files, err := runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select Screenshots",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Images (*.png;*.jpg;*.jpeg)", Pattern: "*.png;*.jpg;*.jpeg"},
+		},
+	})
+	if err != nil || len(files) == 0 {
+		return a.session.Package(), err
+	}
+	if err := a.session.AddScreenshots(files...); err != nil {
+		return nil, err
+	}
+	return a.session.Package(), nil
 }
 
 // LoadController loads an existing controller from the repository
 func (a *App) LoadController(id string) (*utils.ParsedPackage, error) {
-	if a.manager == nil {
-		return nil, fmt.Errorf("repository not selected")
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session.LoadController(id)
+}
+
+// GetConflicts lists the facts that disagree between index.json,
+// version.json and the layout of the current package
+func (a *App) GetConflicts() []utils.Conflict {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	pkg := a.session.Package()
+	if pkg == nil {
+		return []utils.Conflict{}
+	}
+	return pkg.Conflicts()
+}
+
+// ResolveConflict copies the value of field from source into the other
+// files of the current package
+func (a *App) ResolveConflict(field, source string) (*utils.ParsedPackage, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.session.ResolveConflict(field, source); err != nil {
+		return nil, err
+	}
+	return a.session.Package(), nil
+}
+
+// GetFindings returns the audit findings for the current package
+func (a *App) GetFindings() []audit.Finding {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session.Findings()
+}
+
+// ApplyUpdate applies the current package update to the repository
+func (a *App) ApplyUpdate(selectedCategories []int, devices []models.Device, author, description string, locales []models.LocalizedEntry, reviewer, notes string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.session.Manager() == nil || a.session.Package() == nil {
+		return fmt.Errorf("repo or package not selected")
+	}
+	err := a.session.EditMetadata(service.Metadata{
+		Categories:  selectedCategories,
+		Devices:     devices,
+		Author:      author,
+		Description: description,
+		Locales:     locales,
+	})
+	if err != nil {
+		return err
 	}
+	return a.session.Apply(reviewer, notes)
+}
 
-	version, layout, err := a.manager.LoadControllerDetails(id)
+// EditMetadata writes the reviewer's edits into the current package as one
+// undoable step without applying it
+func (a *App) EditMetadata(selectedCategories []int, devices []models.Device, author, description string, locales []models.LocalizedEntry) (*utils.ParsedPackage, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	err := a.session.EditMetadata(service.Metadata{
+		Categories:  selectedCategories,
+		Devices:     devices,
+		Author:      author,
+		Description: description,
+		Locales:     locales,
+	})
 	if err != nil {
 		return nil, err
 	}
+	return a.session.Package(), nil
+}
+
+// UndoEdit reverts the most recent edit to the current package
+func (a *App) UndoEdit() (*utils.ParsedPackage, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.session.Undo(); err != nil {
+		return nil, err
+	}
+	return a.session.Package(), nil
+}
+
+// RedoEdit re-applies the most recently undone edit
+func (a *App) RedoEdit() (*utils.ParsedPackage, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.session.Redo(); err != nil {
+		return nil, err
+	}
+	return a.session.Package(), nil
+}
 
-	pkg := &utils.ParsedPackage{
-		ControllerID: id,
-		VersionInfo:  version,
-		IsUpdate:     true,
+// ResetEdits restores the values the current package was submitted with
+func (a *App) ResetEdits() (*utils.ParsedPackage, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.session.ResetToSubmitted(); err != nil {
+		return nil, err
 	}
+	return a.session.Package(), nil
+}
 
-	if layout != nil {
-		lData, _ := json.Marshal(layout)
-		var cl models.ControllerLayout
-		json.Unmarshal(lData, &cl)
-		pkg.Layout = &cl
-		pkg.VersionCode = cl.VersionCode
+// GetEditState reports which of undo, redo and reset are available
+func (a *App) GetEditState() service.EditState {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session.EditState()
+}
+
+// ProposeFixes lists the changes the selected layout fixers would make;
+// no fixers selected means all of them
+func (a *App) ProposeFixes(fixers []string, buttonStyle, directionStyle string) ([]audit.Change, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session.ProposeFixes(audit.FixOptions{Fixers: fixers, ButtonStyle: buttonStyle, DirectionStyle: directionStyle})
+}
+
+// ApplyFixes makes the changes listed by ProposeFixes as one undoable edit
+func (a *App) ApplyFixes(fixers []string, buttonStyle, directionStyle string) (*utils.ParsedPackage, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.session.ApplyFixes(audit.FixOptions{Fixers: fixers, ButtonStyle: buttonStyle, DirectionStyle: directionStyle}); err != nil {
+		return nil, err
 	}
+	return a.session.Package(), nil
+}
 
-	if version != nil && pkg.VersionCode == 0 {
-		pkg.VersionCode = version.Latest.VersionCode
+// GetDeviceSuggestions judges the current layout on every known device type
+func (a *App) GetDeviceSuggestions() []audit.DeviceSuggestion {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	pkg := a.session.Package()
+	if pkg == nil {
+		return []audit.DeviceSuggestion{}
 	}
+	return audit.SuggestDevices(pkg.Layout)
+}
 
-	// Find in index
-	for _, entry := range a.manager.Index {
-		if entry.ID == id {
-			copyEntry := entry
-			pkg.IndexEntry = &copyEntry
-			pkg.CurrentIndex = &copyEntry
-			break
-		}
+// GetLayoutMetrics measures the size and complexity of the current layout
+func (a *App) GetLayoutMetrics() audit.Metrics {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	pkg := a.session.Package()
+	if pkg == nil {
+		return audit.ComputeMetrics(nil)
+	}
+	return audit.ComputeMetrics(pkg.Layout)
+}
+
+// GetSimilarControllers returns the published controllers whose layouts
+// most resemble the current one, closest first
+func (a *App) GetSimilarControllers() ([]audit.Match, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session.SimilarControllers(5)
+}
+
+// GetComparisonImage renders the current layout next to a published one as
+// a base64 PNG, with the matched elements outlined
+func (a *App) GetComparisonImage(id string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	img, _, err := a.session.Comparison(id)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// GetRequiredLocales returns the locales every controller must be named in
+func (a *App) GetRequiredLocales() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session.RequiredLocales()
+}
+
+// GetAuditLog returns the recorded review decisions for a controller
+func (a *App) GetAuditLog(id string) ([]models.AuditRecord, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	mgr, err := a.session.Repo()
+	if err != nil {
+		return nil, err
 	}
+	return mgr.AuditLog(id)
+}
 
-	basePath := filepath.Join(a.manager.RepoRoot, "repo_json", id)
-	iconPath := filepath.Join(basePath, "icon.png")
-	if _, err := os.Stat(iconPath); err == nil {
-		pkg.IconPath = iconPath
+// GenerateRejectionReport writes the feedback documents for the current
+// package into a user-selected directory and records the rejection
+func (a *App) GenerateRejectionReport(reviewer, notes string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.session.Package() == nil {
+		return "", service.ErrNoPackage
+	}
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select Output Folder for Rejection Report",
+	})
+	if err != nil || dir == "" {
+		return "", err
 	}
 
-	screenshotDir := filepath.Join(basePath, "screenshots")
-	if files, err := os.ReadDir(screenshotDir); err == nil {
-		for _, f := range files {
-			if !f.IsDir() && (strings.HasSuffix(f.Name(), ".png") || strings.HasSuffix(f.Name(), ".jpg")) {
-				pkg.Screenshots = append(pkg.Screenshots, filepath.Join(screenshotDir, f.Name()))
-			}
+	if files, err := a.session.Reject(dir, reviewer, notes); err != nil {
+		if len(files) > 0 {
+			return dir, err
 		}
+		return "", err
 	}
+	return dir, nil
+}
 
-	a.pkg = pkg
-	return pkg, nil
+// RollbackController promotes a historical version of a controller back to latest
+func (a *App) RollbackController(id string, versionCode int, reviewer, notes string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session.Rollback(id, versionCode, repository.Review{
+		Reviewer: reviewer,
+		Notes:    notes,
+	})
+}
+
+// RemoveController removes a controller from the index, archiving its files
+func (a *App) RemoveController(id string, archive bool, reviewer, notes string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session.RemoveController(id, archive, repository.Review{Reviewer: reviewer, Notes: notes})
+}
+
+// DeprecateController marks a controller deprecated with a reason and optional replacement
+func (a *App) DeprecateController(id, reason, replacedBy, reviewer string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session.DeprecateController(id, reason, replacedBy, repository.Review{Reviewer: reviewer})
+}
+
+// UndeprecateController clears a controller's deprecation
+func (a *App) UndeprecateController(id, reviewer string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session.UndeprecateController(id, repository.Review{Reviewer: reviewer})
+}
+
+// CheckRepository reports dangling references inside the repository
+func (a *App) CheckRepository() ([]string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	mgr, err := a.session.Repo()
+	if err != nil {
+		return nil, err
+	}
+	return mgr.CheckConsistency(), nil
 }
 
-// GetImageBase64 returns the base64 string of an image file
-func (a *App) GetImageBase64(path string) (string, error) {
-	data, err := os.ReadFile(path)
+// GenerateAnalyticsReport writes the repository overview as HTML and CSV
+// into a chosen folder and returns the folder
+func (a *App) GenerateAnalyticsReport() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	mgr, err := a.session.Repo()
 	if err != nil {
 		return "", err
 	}
-	return base64.StdEncoding.EncodeToString(data), nil
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select Output Folder for Analytics Report",
+	})
+	if err != nil || dir == "" {
+		return "", err
+	}
+	analytics, err := report.NewAnalytics(mgr)
+	if err != nil {
+		return "", err
+	}
+	if _, err := analytics.Write(dir); err != nil {
+		return "", err
+	}
+	return dir, nil
 }
 
-// ApplyUpdate applies the current package update to the repository
-func (a *App) ApplyUpdate(selectedCategories []int, author, description, name, intro string) error {
-	if a.manager == nil || a.pkg == nil {
-		return fmt.Errorf("repo or package not selected")
+// SetGitEnabled turns committing applied updates to git on or off
+func (a *App) SetGitEnabled(enabled bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	mgr, err := a.session.Repo()
+	if err != nil {
+		return err
 	}
-	if a.pkg.IndexEntry != nil {
-		a.pkg.IndexEntry.Categories = selectedCategories
-		if name != "" {
-			a.pkg.IndexEntry.Name = name
-		}
-		if intro != "" {
-			a.pkg.IndexEntry.Introduction = intro
-		}
+	if !enabled {
+		mgr.DisableGit()
+		return nil
 	}
-	if a.pkg.VersionInfo != nil {
-		if author != "" {
-			a.pkg.VersionInfo.Author = author
-		}
-		if description != "" {
-			a.pkg.VersionInfo.Description = description
-		}
+	return mgr.EnableGit()
+}
+
+// SetNormalizeIcons turns resizing icons to the canonical size on or off
+func (a *App) SetNormalizeIcons(enabled bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	mgr, err := a.session.Repo()
+	if err != nil {
+		return err
+	}
+	mgr.NormalizeIcons = enabled
+	return nil
+}
+
+// SetDownscaleScreenshots turns shrinking oversized screenshots on or off
+func (a *App) SetDownscaleScreenshots(enabled bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	mgr, err := a.session.Repo()
+	if err != nil {
+		return err
 	}
-	return a.manager.ApplyUpdate(a.pkg)
+	mgr.DownscaleScreenshots = enabled
+	return nil
+}
+
+// GetGitHistory returns the git commits touching a controller's directory
+func (a *App) GetGitHistory(id string) ([]vcs.Commit, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	mgr, err := a.session.Repo()
+	if err != nil {
+		return nil, err
+	}
+	return mgr.ControllerHistory(id)
+}
+
+// ExportController saves a controller as a submission ZIP; versionCode 0
+// exports the latest version
+func (a *App) ExportController(id string, versionCode int) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	mgr, err := a.session.Repo()
+	if err != nil {
+		return "", err
+	}
+	dest, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Controller Package",
+		DefaultFilename: id + ".zip",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "ZIP Files (*.zip)", Pattern: "*.zip"},
+		},
+	})
+	if err != nil || dest == "" {
+		return "", err
+	}
+	return dest, mgr.ExportControllerZip(id, versionCode, dest)
 }
 
 // GetCategories returns the available categories from category.json
 func (a *App) GetCategories() []models.Category {
-	if a.manager == nil {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	mgr := a.session.Manager()
+	if mgr == nil {
 		return nil
 	}
-	return a.manager.Categories
+	return mgr.Categories
+}
+
+// CreateCategory adds a category; a null id picks the next free ID
+func (a *App) CreateCategory(id *int, texts []models.LocalizedText, reviewer, notes string) (models.Category, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session.CreateCategory(id, texts, repository.Review{Reviewer: reviewer, Notes: notes})
+}
+
+// RenameCategory updates the locale names of a category
+func (a *App) RenameCategory(id int, texts []models.LocalizedText, reviewer, notes string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session.RenameCategory(id, texts, repository.Review{Reviewer: reviewer, Notes: notes})
+}
+
+// MergeCategories moves every controller from one category into another
+func (a *App) MergeCategories(from, into int, reviewer, notes string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session.MergeCategories(from, into, repository.Review{Reviewer: reviewer, Notes: notes})
+}
+
+// DeleteCategory removes a category, reassigning its controllers unless reassignTo is null
+func (a *App) DeleteCategory(id int, reassignTo *int, reviewer, notes string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.session.DeleteCategory(id, reassignTo, repository.Review{Reviewer: reviewer, Notes: notes})
 }
 
 // GetRepoIndex returns the current repository index
 func (a *App) GetRepoIndex() []models.IndexEntry {
-	if a.manager == nil {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	mgr := a.session.Manager()
+	if mgr == nil {
 		return nil
 	}
-	return a.manager.Index
+	return mgr.Index
 }
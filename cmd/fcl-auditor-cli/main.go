@@ -0,0 +1,11 @@
+package main
+
+import (
+	"os"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
@@ -1,18 +1,106 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {audit} from '../models';
 import {models} from '../models';
+import {service} from '../models';
+import {settings} from '../models';
 import {utils} from '../models';
+import {vcs} from '../models';
 
-export function ApplyUpdate(arg1:Array<number>,arg2:string,arg3:string,arg4:string,arg5:string):Promise<void>;
+export function AddPackageScreenshots():Promise<utils.ParsedPackage>;
+
+export function ApplyFixes(arg1:Array<string>,arg2:string,arg3:string):Promise<utils.ParsedPackage>;
+
+export function ApplyUpdate(arg1:Array<number>,arg2:Array<number>,arg3:string,arg4:string,arg5:Array<models.LocalizedEntry>,arg6:string,arg7:string):Promise<void>;
+
+export function CheckRepository():Promise<Array<string>>;
+
+export function CreateCategory(arg1:number|null,arg2:Array<models.LocalizedText>,arg3:string,arg4:string):Promise<models.Category>;
+
+export function DeleteCategory(arg1:number,arg2:number|null,arg3:string,arg4:string):Promise<void>;
+
+export function DeprecateController(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function EditMetadata(arg1:Array<number>,arg2:Array<number>,arg3:string,arg4:string,arg5:Array<models.LocalizedEntry>):Promise<utils.ParsedPackage>;
+
+export function ExportController(arg1:string,arg2:number):Promise<string>;
+
+export function GenerateAnalyticsReport():Promise<string>;
+
+export function GenerateRejectionReport(arg1:string,arg2:string):Promise<string>;
+
+export function GetAuditLog(arg1:string):Promise<Array<models.AuditRecord>>;
 
 export function GetCategories():Promise<Array<models.Category>>;
 
-export function GetImageBase64(arg1:string):Promise<string>;
+export function GetComparisonImage(arg1:string):Promise<string>;
+
+export function GetConflicts():Promise<Array<utils.Conflict>>;
+
+export function GetDeviceSuggestions():Promise<Array<audit.DeviceSuggestion>>;
+
+export function GetEditState():Promise<service.EditState>;
+
+export function GetFindings():Promise<Array<audit.Finding>>;
+
+export function GetGitHistory(arg1:string):Promise<Array<vcs.Commit>>;
+
+export function GetLayoutMetrics():Promise<audit.Metrics>;
+
+export function GetProfileName():Promise<string>;
+
+export function GetProfiles():Promise<Array<string>>;
 
 export function GetRepoIndex():Promise<Array<models.IndexEntry>>;
 
+export function GetRepoRoot():Promise<string>;
+
+export function GetRequiredLocales():Promise<Array<string>>;
+
+export function GetSettings():Promise<settings.Settings>;
+
+export function GetSimilarControllers():Promise<Array<audit.Match>>;
+
 export function LoadController(arg1:string):Promise<utils.ParsedPackage>;
 
+export function MergeCategories(arg1:number,arg2:number,arg3:string,arg4:string):Promise<void>;
+
+export function NewFromLayout():Promise<utils.ParsedPackage>;
+
+export function OpenRecentRepo(arg1:string):Promise<string>;
+
+export function ProposeFixes(arg1:Array<string>,arg2:string,arg3:string):Promise<Array<audit.Change>>;
+
+export function RedoEdit():Promise<utils.ParsedPackage>;
+
+export function RemoveController(arg1:string,arg2:boolean,arg3:string,arg4:string):Promise<void>;
+
+export function RenameCategory(arg1:number,arg2:Array<models.LocalizedText>,arg3:string,arg4:string):Promise<void>;
+
+export function ResetEdits():Promise<utils.ParsedPackage>;
+
+export function ResolveConflict(arg1:string,arg2:string):Promise<utils.ParsedPackage>;
+
+export function RollbackController(arg1:string,arg2:number,arg3:string,arg4:string):Promise<void>;
+
+export function SelectInboxDir():Promise<string>;
+
+export function SelectPackageIcon():Promise<utils.ParsedPackage>;
+
+export function SelectProfile(arg1:string):Promise<void>;
+
 export function SelectRepoRoot():Promise<string>;
 
 export function SelectZip():Promise<utils.ParsedPackage>;
+
+export function SetDownscaleScreenshots(arg1:boolean):Promise<void>;
+
+export function SetGitEnabled(arg1:boolean):Promise<void>;
+
+export function SetNormalizeIcons(arg1:boolean):Promise<void>;
+
+export function UndeprecateController(arg1:string,arg2:string):Promise<void>;
+
+export function UndoEdit():Promise<utils.ParsedPackage>;
+
+export function UpdateSettings(arg1:settings.Settings):Promise<settings.Settings>;
@@ -2,26 +2,170 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
-export function ApplyUpdate(arg1, arg2, arg3, arg4, arg5) {
-  return window['go']['main']['App']['ApplyUpdate'](arg1, arg2, arg3, arg4, arg5);
+export function AddPackageScreenshots() {
+  return window['go']['main']['App']['AddPackageScreenshots']();
+}
+
+export function ApplyFixes(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ApplyFixes'](arg1, arg2, arg3);
+}
+
+export function ApplyUpdate(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
+  return window['go']['main']['App']['ApplyUpdate'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
+}
+
+export function CheckRepository() {
+  return window['go']['main']['App']['CheckRepository']();
+}
+
+export function CreateCategory(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['CreateCategory'](arg1, arg2, arg3, arg4);
+}
+
+export function DeleteCategory(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['DeleteCategory'](arg1, arg2, arg3, arg4);
+}
+
+export function DeprecateController(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['DeprecateController'](arg1, arg2, arg3, arg4);
+}
+
+export function EditMetadata(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['EditMetadata'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function ExportController(arg1, arg2) {
+  return window['go']['main']['App']['ExportController'](arg1, arg2);
+}
+
+export function GenerateAnalyticsReport() {
+  return window['go']['main']['App']['GenerateAnalyticsReport']();
+}
+
+export function GenerateRejectionReport(arg1, arg2) {
+  return window['go']['main']['App']['GenerateRejectionReport'](arg1, arg2);
+}
+
+export function GetAuditLog(arg1) {
+  return window['go']['main']['App']['GetAuditLog'](arg1);
 }
 
 export function GetCategories() {
   return window['go']['main']['App']['GetCategories']();
 }
 
-export function GetImageBase64(arg1) {
-  return window['go']['main']['App']['GetImageBase64'](arg1);
+export function GetComparisonImage(arg1) {
+  return window['go']['main']['App']['GetComparisonImage'](arg1);
+}
+
+export function GetConflicts() {
+  return window['go']['main']['App']['GetConflicts']();
+}
+
+export function GetDeviceSuggestions() {
+  return window['go']['main']['App']['GetDeviceSuggestions']();
+}
+
+export function GetEditState() {
+  return window['go']['main']['App']['GetEditState']();
+}
+
+export function GetFindings() {
+  return window['go']['main']['App']['GetFindings']();
+}
+
+export function GetGitHistory(arg1) {
+  return window['go']['main']['App']['GetGitHistory'](arg1);
+}
+
+export function GetLayoutMetrics() {
+  return window['go']['main']['App']['GetLayoutMetrics']();
+}
+
+export function GetProfileName() {
+  return window['go']['main']['App']['GetProfileName']();
+}
+
+export function GetProfiles() {
+  return window['go']['main']['App']['GetProfiles']();
 }
 
 export function GetRepoIndex() {
   return window['go']['main']['App']['GetRepoIndex']();
 }
 
+export function GetRepoRoot() {
+  return window['go']['main']['App']['GetRepoRoot']();
+}
+
+export function GetRequiredLocales() {
+  return window['go']['main']['App']['GetRequiredLocales']();
+}
+
+export function GetSettings() {
+  return window['go']['main']['App']['GetSettings']();
+}
+
+export function GetSimilarControllers() {
+  return window['go']['main']['App']['GetSimilarControllers']();
+}
+
 export function LoadController(arg1) {
   return window['go']['main']['App']['LoadController'](arg1);
 }
 
+export function MergeCategories(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['MergeCategories'](arg1, arg2, arg3, arg4);
+}
+
+export function NewFromLayout() {
+  return window['go']['main']['App']['NewFromLayout']();
+}
+
+export function OpenRecentRepo(arg1) {
+  return window['go']['main']['App']['OpenRecentRepo'](arg1);
+}
+
+export function ProposeFixes(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ProposeFixes'](arg1, arg2, arg3);
+}
+
+export function RedoEdit() {
+  return window['go']['main']['App']['RedoEdit']();
+}
+
+export function RemoveController(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['RemoveController'](arg1, arg2, arg3, arg4);
+}
+
+export function RenameCategory(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['RenameCategory'](arg1, arg2, arg3, arg4);
+}
+
+export function ResetEdits() {
+  return window['go']['main']['App']['ResetEdits']();
+}
+
+export function ResolveConflict(arg1, arg2) {
+  return window['go']['main']['App']['ResolveConflict'](arg1, arg2);
+}
+
+export function RollbackController(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['RollbackController'](arg1, arg2, arg3, arg4);
+}
+
+export function SelectInboxDir() {
+  return window['go']['main']['App']['SelectInboxDir']();
+}
+
+export function SelectPackageIcon() {
+  return window['go']['main']['App']['SelectPackageIcon']();
+}
+
+export function SelectProfile(arg1) {
+  return window['go']['main']['App']['SelectProfile'](arg1);
+}
+
 export function SelectRepoRoot() {
   return window['go']['main']['App']['SelectRepoRoot']();
 }
@@ -29,3 +173,27 @@ export function SelectRepoRoot() {
 export function SelectZip() {
   return window['go']['main']['App']['SelectZip']();
 }
+
+export function SetDownscaleScreenshots(arg1) {
+  return window['go']['main']['App']['SetDownscaleScreenshots'](arg1);
+}
+
+export function SetGitEnabled(arg1) {
+  return window['go']['main']['App']['SetGitEnabled'](arg1);
+}
+
+export function SetNormalizeIcons(arg1) {
+  return window['go']['main']['App']['SetNormalizeIcons'](arg1);
+}
+
+export function UndeprecateController(arg1, arg2) {
+  return window['go']['main']['App']['UndeprecateController'](arg1, arg2);
+}
+
+export function UndoEdit() {
+  return window['go']['main']['App']['UndoEdit']();
+}
+
+export function UpdateSettings(arg1) {
+  return window['go']['main']['App']['UpdateSettings'](arg1);
+}
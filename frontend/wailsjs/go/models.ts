@@ -1,5 +1,271 @@
+export namespace audit {
+	
+	export class Change {
+	    fixer: string;
+	    elementId?: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Change(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.fixer = source["fixer"];
+	        this.elementId = source["elementId"];
+	        this.message = source["message"];
+	    }
+	}
+	export class DeviceSuggestion {
+	    device: number;
+	    name: string;
+	    suitable: boolean;
+	    reasons: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DeviceSuggestion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.device = source["device"];
+	        this.name = source["name"];
+	        this.suitable = source["suitable"];
+	        this.reasons = source["reasons"];
+	    }
+	}
+	export class Finding {
+	    ruleId: string;
+	    severity: string;
+	    elementId?: string;
+	    message: string;
+	    values?: utils.SourceValue[];
+	    suppressed?: boolean;
+	    justification?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Finding(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ruleId = source["ruleId"];
+	        this.severity = source["severity"];
+	        this.elementId = source["elementId"];
+	        this.message = source["message"];
+	        this.values = this.convertValues(source["values"], utils.SourceValue);
+	        this.suppressed = source["suppressed"];
+	        this.justification = source["justification"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TargetSize {
+	    screen: string;
+	    dpi: number;
+	    elementId: string;
+	    mm: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TargetSize(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.screen = source["screen"];
+	        this.dpi = source["dpi"];
+	        this.elementId = source["elementId"];
+	        this.mm = source["mm"];
+	    }
+	}
+	export class Metrics {
+	    groups: number;
+	    buttons: number;
+	    directions: number;
+	    buttonStyles: number;
+	    directionStyles: number;
+	    keycodes: number;
+	    coverage: number;
+	    density: number[][];
+	    smallestTargets: TargetSize[];
+	    toggleDepth: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Metrics(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.groups = source["groups"];
+	        this.buttons = source["buttons"];
+	        this.directions = source["directions"];
+	        this.buttonStyles = source["buttonStyles"];
+	        this.directionStyles = source["directionStyles"];
+	        this.keycodes = source["keycodes"];
+	        this.coverage = source["coverage"];
+	        this.density = source["density"];
+	        this.smallestTargets = this.convertValues(source["smallestTargets"], TargetSize);
+	        this.toggleDepth = source["toggleDepth"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Pair {
+	    submitted: string;
+	    published: string;
+	    score: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Pair(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.submitted = source["submitted"];
+	        this.published = source["published"];
+	        this.score = source["score"];
+	    }
+	}
+	export class Match {
+	    id: string;
+	    score: number;
+	    pairs: Pair[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Match(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.score = source["score"];
+	        this.pairs = this.convertValues(source["pairs"], Pair);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
 export namespace models {
 	
+	export class FindingsSummary {
+	    errors: number;
+	    warnings: number;
+	    info: number;
+	    suppressed?: number;
+	    rules: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FindingsSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.errors = source["errors"];
+	        this.warnings = source["warnings"];
+	        this.info = source["info"];
+	        this.suppressed = source["suppressed"];
+	        this.rules = source["rules"];
+	    }
+	}
+	export class AuditRecord {
+	    // Go type: time
+	    timestamp: any;
+	    action: string;
+	    controllerId: string;
+	    category?: number;
+	    oldVersionCode: number;
+	    newVersionCode: number;
+	    reviewer: string;
+	    packageSha256?: string;
+	    findings: FindingsSummary;
+	    notes?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.timestamp = this.convertValues(source["timestamp"], null);
+	        this.action = source["action"];
+	        this.controllerId = source["controllerId"];
+	        this.category = source["category"];
+	        this.oldVersionCode = source["oldVersionCode"];
+	        this.newVersionCode = source["newVersionCode"];
+	        this.reviewer = source["reviewer"];
+	        this.packageSha256 = source["packageSha256"];
+	        this.findings = this.convertValues(source["findings"], FindingsSummary);
+	        this.notes = source["notes"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Percentage {
 	    reference: string;
 	    size: number;
@@ -194,12 +460,12 @@ export namespace models {
 	        this.fillColorPressed = source["fillColorPressed"];
 	    }
 	}
-	export class  {
+	export class LocalizedText {
 	    locale: string;
 	    text: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new (source);
+	        return new LocalizedText(source);
 	    }
 	
 	    constructor(source: any = {}) {
@@ -210,7 +476,7 @@ export namespace models {
 	}
 	export class Category {
 	    id: number;
-	    lang: [];
+	    lang: LocalizedText[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Category(source);
@@ -219,7 +485,7 @@ export namespace models {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
-	        this.lang = this.convertValues(source["lang"], );
+	        this.lang = this.convertValues(source["lang"], LocalizedText);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -457,6 +723,38 @@ export namespace models {
 	
 	
 	
+	export class Deprecation {
+	    reason: string;
+	    replacedBy?: string;
+	    date: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Deprecation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.reason = source["reason"];
+	        this.replacedBy = source["replacedBy"];
+	        this.date = source["date"];
+	    }
+	}
+	export class LocalizedEntry {
+	    locale: string;
+	    name: string;
+	    introduction: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LocalizedEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.locale = source["locale"];
+	        this.name = source["name"];
+	        this.introduction = source["introduction"];
+	    }
+	}
 	export class IndexEntry {
 	    id: string;
 	    lang: string;
@@ -464,6 +762,8 @@ export namespace models {
 	    introduction: string;
 	    device: number[];
 	    categories: number[];
+	    deprecated?: Deprecation;
+	    translations?: LocalizedEntry[];
 	
 	    static createFrom(source: any = {}) {
 	        return new IndexEntry(source);
@@ -477,7 +777,27 @@ export namespace models {
 	        this.introduction = source["introduction"];
 	        this.device = source["device"];
 	        this.categories = source["categories"];
+	        this.deprecated = this.convertValues(source["deprecated"], Deprecation);
+	        this.translations = this.convertValues(source["translations"], LocalizedEntry);
 	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	
 	
@@ -537,10 +857,148 @@ export namespace models {
 	
 	
 
+}
+
+export namespace service {
+	
+	export class EditState {
+	    canUndo: boolean;
+	    canRedo: boolean;
+	    modified: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new EditState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.canUndo = source["canUndo"];
+	        this.canRedo = source["canRedo"];
+	        this.modified = source["modified"];
+	    }
+	}
+
+}
+
+export namespace settings {
+	
+	export class Audit {
+	    profile?: string;
+	    disabledRules?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Audit(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.profile = source["profile"];
+	        this.disabledRules = source["disabledRules"];
+	    }
+	}
+	export class Resolution {
+	    width: number;
+	    height: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Resolution(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.width = source["width"];
+	        this.height = source["height"];
+	    }
+	}
+	export class Settings {
+	    recentRepos: string[];
+	    reviewer: string;
+	    inboxDir: string;
+	    audit: Audit;
+	    preview: Resolution;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.recentRepos = source["recentRepos"];
+	        this.reviewer = source["reviewer"];
+	        this.inboxDir = source["inboxDir"];
+	        this.audit = this.convertValues(source["audit"], Audit);
+	        this.preview = this.convertValues(source["preview"], Resolution);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
 }
 
 export namespace utils {
 	
+	export class SourceValue {
+	    source: string;
+	    value: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SourceValue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.value = source["value"];
+	    }
+	}
+	export class Conflict {
+	    field: string;
+	    values: SourceValue[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Conflict(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.values = this.convertValues(source["values"], SourceValue);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class ParsedPackage {
 	    ControllerID: string;
 	    VersionCode: number;
@@ -552,6 +1010,8 @@ export namespace utils {
 	    TempDir: string;
 	    IsUpdate: boolean;
 	    CurrentIndex?: models.IndexEntry;
+	    SourcePath: string;
+	    SHA256: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new ParsedPackage(source);
@@ -569,6 +1029,8 @@ export namespace utils {
 	        this.TempDir = source["TempDir"];
 	        this.IsUpdate = source["IsUpdate"];
 	        this.CurrentIndex = this.convertValues(source["CurrentIndex"], models.IndexEntry);
+	        this.SourcePath = source["SourcePath"];
+	        this.SHA256 = source["SHA256"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -592,3 +1054,45 @@ export namespace utils {
 
 }
 
+export namespace vcs {
+	
+	export class Commit {
+	    hash: string;
+	    author: string;
+	    // Go type: time
+	    date: any;
+	    subject: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Commit(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hash = source["hash"];
+	        this.author = source["author"];
+	        this.date = this.convertValues(source["date"], null);
+	        this.subject = source["subject"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
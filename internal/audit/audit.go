@@ -0,0 +1,159 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Finding is a single problem reported by a rule. ElementID points at the
+// view group, button or direction the finding is about, if any. Values holds
+// the disagreeing sources of a consistency finding so reports can word it in
+// the submitter's language. Suppressed findings were accepted by a reviewer;
+// Justification says why.
+type Finding struct {
+	RuleID        string              `json:"ruleId"`
+	Severity      Severity            `json:"severity"`
+	ElementID     string              `json:"elementId,omitempty"`
+	Message       string              `json:"message"`
+	Values        []utils.SourceValue `json:"values,omitempty"`
+	Suppressed    bool                `json:"suppressed,omitempty"`
+	Justification string              `json:"justification,omitempty"`
+}
+
+// Rule is a named check run against a parsed package. Rules that compare the
+// package with the repository set RepoCheck instead of Check, and rules
+// driven by profile thresholds set LimitCheck.
+type Rule struct {
+	ID         string
+	Severity   Severity
+	Check      func(pkg *utils.ParsedPackage) []Finding
+	RepoCheck  func(pkg *utils.ParsedPackage, repo *Repo) []Finding
+	LimitCheck func(pkg *utils.ParsedPackage, limits Thresholds) []Finding
+}
+
+// Run runs every registered rule against the package and returns the
+// findings ordered by severity, then rule ID.
+func Run(pkg *utils.ParsedPackage) []Finding {
+	return RunRepo(pkg, nil)
+}
+
+// RunRepo is Run with the repository rules enabled. A nil repo skips them.
+func RunRepo(pkg *utils.ParsedPackage, repo *Repo) []Finding {
+	return RunProfile(pkg, repo, nil)
+}
+
+// RunProfile is RunRepo under a rule profile. A nil profile runs every rule
+// at its own severity with DefaultThresholds.
+func RunProfile(pkg *utils.ParsedPackage, repo *Repo, profile *Profile) []Finding {
+	if pkg == nil {
+		return nil
+	}
+	limits := profile.Limits()
+	var findings []Finding
+	for _, r := range Rules {
+		if !profile.Enabled(r.ID) {
+			continue
+		}
+		var found []Finding
+		switch {
+		case r.Check != nil:
+			found = r.Check(pkg)
+		case r.RepoCheck != nil && repo != nil:
+			found = r.RepoCheck(pkg, repo)
+		case r.LimitCheck != nil:
+			found = r.LimitCheck(pkg, limits)
+		}
+		for _, f := range found {
+			f.RuleID = r.ID
+			if f.Severity == "" {
+				f.Severity = r.Severity
+			}
+			if profile != nil && profile.Severity[r.ID] != "" {
+				f.Severity = profile.Severity[r.ID]
+			}
+			findings = append(findings, f)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if severityRank(findings[i].Severity) != severityRank(findings[j].Severity) {
+			return severityRank(findings[i].Severity) < severityRank(findings[j].Severity)
+		}
+		return findings[i].RuleID < findings[j].RuleID
+	})
+	return findings
+}
+
+// Summarize condenses findings into the counts stored in the audit log.
+// Suppressed findings are only counted as such.
+func Summarize(findings []Finding) models.FindingsSummary {
+	summary := models.FindingsSummary{Rules: []string{}}
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		switch {
+		case f.Suppressed:
+			summary.Suppressed++
+			continue
+		case f.Severity == SeverityError:
+			summary.Errors++
+		case f.Severity == SeverityWarning:
+			summary.Warnings++
+		default:
+			summary.Info++
+		}
+		if !seen[f.RuleID] {
+			seen[f.RuleID] = true
+			summary.Rules = append(summary.Rules, f.RuleID)
+		}
+	}
+	return summary
+}
+
+// HasErrors reports whether any unsuppressed finding has error severity.
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError && !f.Suppressed {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Finding) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s", f.Severity, f.RuleID)
+	if f.ElementID != "" {
+		fmt.Fprintf(&b, " (%s)", f.ElementID)
+	}
+	fmt.Fprintf(&b, ": %s", f.Message)
+	if f.Suppressed {
+		fmt.Fprintf(&b, " [suppressed: %s]", f.Justification)
+	}
+	return b.String()
+}
+
+func (s Severity) valid() bool {
+	return s == SeverityError || s == SeverityWarning || s == SeverityInfo
+}
+
+func severityRank(s Severity) int {
+	switch s {
+	case SeverityError:
+		return 0
+	case SeverityWarning:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"fmt"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+// MinTargetDP is the smallest button side, in dp, that is still usable with
+// a thumb.
+const MinTargetDP = 32
+
+// DeviceSuggestion says whether a layout suits a device type and why not.
+type DeviceSuggestion struct {
+	Device   models.Device `json:"device"`
+	Name     string        `json:"name"`
+	Suitable bool          `json:"suitable"`
+	Reasons  []string      `json:"reasons"`
+}
+
+type element struct {
+	id    string
+	group string
+	info  models.BaseInfo
+}
+
+// SuggestDevices judges the layout on the reference screen of every known
+// device type. Elements that end up smaller than MinTargetDP, run off the
+// screen, or overlap another element because of an absolute size make the
+// layout unsuitable for that device. Overlaps only count between groups
+// that can be shown together.
+func SuggestDevices(layout *models.ControllerLayout) []DeviceSuggestion {
+	var elements []element
+	together := func(a, b string) bool { return false }
+	if layout != nil {
+		together = shownTogether(layout)
+		for _, group := range layout.ViewGroups {
+			for _, btn := range group.ViewData.ButtonList {
+				elements = append(elements, element{btn.ID, group.ID, btn.BaseInfo})
+			}
+			for _, dir := range group.ViewData.DirectionList {
+				elements = append(elements, element{dir.ID, group.ID, dir.BaseInfo})
+			}
+		}
+	}
+
+	suggestions := make([]DeviceSuggestion, 0, len(models.Devices))
+	for _, d := range models.Devices {
+		screen := models.DeviceScreens[d]
+		s := DeviceSuggestion{Device: d, Name: d.String(), Reasons: []string{}}
+
+		type rect struct{ x, y, w, h float64 }
+		rects := make([]rect, len(elements))
+		for i, e := range elements {
+			x, y, w, h := e.info.Rect(screen.Width, screen.Height)
+			rects[i] = rect{x, y, w, h}
+			if outOfBounds(e.info.XPosition, e.info.YPosition) {
+				continue // reported by layout.out-of-bounds
+			}
+			if w < MinTargetDP || h < MinTargetDP {
+				s.Reasons = append(s.Reasons, fmt.Sprintf("%q is only %.0fx%.0f dp", e.id, w, h))
+			} else if x+w > screen.Width || y+h > screen.Height {
+				s.Reasons = append(s.Reasons, fmt.Sprintf("%q runs off the %.0fx%.0f dp screen", e.id, screen.Width, screen.Height))
+			}
+		}
+		for i := range elements {
+			for j := i + 1; j < len(elements); j++ {
+				if elements[i].info.SizeType != models.SizeAbsolute && elements[j].info.SizeType != models.SizeAbsolute {
+					continue
+				}
+				if !together(elements[i].group, elements[j].group) {
+					continue
+				}
+				a, b := rects[i], rects[j]
+				if a.x < b.x+b.w && b.x < a.x+a.w && a.y < b.y+b.h && b.y < a.y+a.h {
+					s.Reasons = append(s.Reasons, fmt.Sprintf("%q overlaps %q", elements[i].id, elements[j].id))
+				}
+			}
+		}
+
+		s.Suitable = len(s.Reasons) == 0
+		suggestions = append(suggestions, s)
+	}
+	return suggestions
+}
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+func absButton(id string, x, y int, binds ...string) models.Button {
+	return models.Button{
+		ID: id,
+		BaseInfo: models.BaseInfo{
+			XPosition: x, YPosition: y,
+			SizeType:      models.SizeAbsolute,
+			AbsoluteWidth: 60, AbsoluteHeight: 60,
+		},
+		Event: models.Event{PressEvent: models.PressEvent{BindViewGroup: binds}},
+	}
+}
+
+func group(id string, shown bool, buttons ...models.Button) models.ViewGroup {
+	g := models.ViewGroup{ID: id, ViewData: models.ViewData{ButtonList: buttons}}
+	if shown {
+		g.Visibility = models.VisibilityShown
+	}
+	return g
+}
+
+func TestSuggestDevicesOverlaps(t *testing.T) {
+	tests := []struct {
+		name    string
+		groups  []models.ViewGroup
+		overlap bool
+	}{
+		{
+			name:    "same group",
+			groups:  []models.ViewGroup{group("main", true, absButton("a", 100, 100), absButton("b", 110, 110))},
+			overlap: true,
+		},
+		{
+			name: "both shown at start",
+			groups: []models.ViewGroup{
+				group("main", true, absButton("a", 100, 100)),
+				group("extra", true, absButton("b", 110, 110)),
+			},
+			overlap: true,
+		},
+		{
+			name: "toggled in next to the shown group",
+			groups: []models.ViewGroup{
+				group("main", true, absButton("a", 100, 100), absButton("toggle", 900, 900, "extra")),
+				group("extra", false, absButton("b", 110, 110)),
+			},
+			overlap: true,
+		},
+		{
+			name: "swapped by one button",
+			groups: []models.ViewGroup{
+				group("menu", true, absButton("swap", 900, 900, "walk", "drive")),
+				group("walk", true, absButton("a", 100, 100)),
+				group("drive", false, absButton("b", 110, 110)),
+			},
+		},
+		{
+			name: "never shown",
+			groups: []models.ViewGroup{
+				group("main", true, absButton("a", 100, 100)),
+				group("unused", false, absButton("b", 110, 110)),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &models.ControllerLayout{ViewGroups: tt.groups}
+			for _, s := range SuggestDevices(layout) {
+				overlap := false
+				for _, r := range s.Reasons {
+					if strings.Contains(r, `"a" overlaps "b"`) {
+						overlap = true
+					}
+				}
+				if overlap != tt.overlap {
+					t.Errorf("%s: overlap reported = %v, want %v (reasons %v)", s.Name, overlap, tt.overlap, s.Reasons)
+				}
+			}
+		})
+	}
+}
+
+func TestDevicesAreClientDefined(t *testing.T) {
+	for _, d := range []models.Device{models.DevicePhone, models.DeviceTablet} {
+		if !d.Valid() {
+			t.Errorf("%v is not valid", d)
+		}
+	}
+	if models.Device(2).Valid() {
+		t.Error("device type 2 is not defined by the client")
+	}
+	if got := len(SuggestDevices(nil)); got != len(models.Devices) {
+		t.Errorf("SuggestDevices(nil) returned %d suggestions, want %d", got, len(models.Devices))
+	}
+}
@@ -0,0 +1,328 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+// Fixer names, in the order Fix runs them.
+const (
+	FixVisibilityCase    = "visibility-case"
+	FixDuplicateIDs      = "duplicate-ids"
+	FixEmptyGroups       = "empty-groups"
+	FixUnknownStyles     = "unknown-styles"
+	FixDuplicateKeycodes = "duplicate-keycodes"
+	FixClampPositions    = "clamp-positions"
+)
+
+// Fixers lists every fixer Fix knows.
+var Fixers = []string{
+	FixVisibilityCase,
+	FixDuplicateIDs,
+	FixEmptyGroups,
+	FixUnknownStyles,
+	FixDuplicateKeycodes,
+	FixClampPositions,
+}
+
+// Change is one edit made by a fixer.
+type Change struct {
+	Fixer     string `json:"fixer"`
+	ElementID string `json:"elementId,omitempty"`
+	Message   string `json:"message"`
+}
+
+func (c Change) String() string {
+	if c.ElementID != "" {
+		return fmt.Sprintf("[%s] (%s) %s", c.Fixer, c.ElementID, c.Message)
+	}
+	return fmt.Sprintf("[%s] %s", c.Fixer, c.Message)
+}
+
+// FixOptions selects the fixers to run. ButtonStyle and DirectionStyle
+// replace unknown style references; empty uses the first style the layout
+// defines.
+type FixOptions struct {
+	Fixers         []string `json:"fixers"`
+	ButtonStyle    string   `json:"buttonStyle"`
+	DirectionStyle string   `json:"directionStyle"`
+}
+
+func (o FixOptions) enabled(name string) bool {
+	return len(o.Fixers) == 0 || slices.Contains(o.Fixers, name)
+}
+
+// Fix runs the selected fixers on a copy of layout and returns the copy
+// with the changes made. layout itself is left untouched, so the reviewer
+// can inspect the change list before adopting the result.
+func Fix(layout *models.ControllerLayout, opts FixOptions) (*models.ControllerLayout, []Change, error) {
+	if layout == nil {
+		return nil, nil, fmt.Errorf("package has no layout")
+	}
+	for _, name := range opts.Fixers {
+		if !slices.Contains(Fixers, name) {
+			return nil, nil, fmt.Errorf("unknown fixer %q", name)
+		}
+	}
+	data, err := json.Marshal(layout)
+	if err != nil {
+		return nil, nil, err
+	}
+	var fixed *models.ControllerLayout
+	if err := json.Unmarshal(data, &fixed); err != nil {
+		return nil, nil, err
+	}
+
+	var changes []Change
+	if opts.enabled(FixVisibilityCase) {
+		changes = append(changes, fixVisibilityCase(fixed)...)
+	}
+	if opts.enabled(FixDuplicateIDs) {
+		changes = append(changes, fixDuplicateIDs(fixed)...)
+	}
+	if opts.enabled(FixEmptyGroups) {
+		changes = append(changes, fixEmptyGroups(fixed)...)
+	}
+	if opts.enabled(FixUnknownStyles) {
+		found, err := fixUnknownStyles(fixed, opts.ButtonStyle, opts.DirectionStyle)
+		if err != nil {
+			return nil, nil, err
+		}
+		changes = append(changes, found...)
+	}
+	if opts.enabled(FixDuplicateKeycodes) {
+		changes = append(changes, fixDuplicateKeycodes(fixed)...)
+	}
+	if opts.enabled(FixClampPositions) {
+		changes = append(changes, fixClampPositions(fixed)...)
+	}
+	return fixed, changes, nil
+}
+
+// fixVisibilityCase upper-cases group visibilities and element visibility
+// types, which FCL compares case-sensitively.
+func fixVisibilityCase(layout *models.ControllerLayout) []Change {
+	var changes []Change
+	normalize := func(id string, v *string) {
+		if want := strings.ToUpper(strings.TrimSpace(*v)); want != *v {
+			changes = append(changes, Change{
+				Fixer:     FixVisibilityCase,
+				ElementID: id,
+				Message:   fmt.Sprintf("visibility %q -> %q", *v, want),
+			})
+			*v = want
+		}
+	}
+	for gi := range layout.ViewGroups {
+		group := &layout.ViewGroups[gi]
+		normalize(group.ID, &group.Visibility)
+		for i := range group.ViewData.ButtonList {
+			btn := &group.ViewData.ButtonList[i]
+			normalize(btn.ID, &btn.BaseInfo.VisibilityType)
+		}
+		for i := range group.ViewData.DirectionList {
+			dir := &group.ViewData.DirectionList[i]
+			normalize(dir.ID, &dir.BaseInfo.VisibilityType)
+		}
+	}
+	return changes
+}
+
+// fixDuplicateIDs renames every repeat of an ID by appending a counter. The
+// first element keeps its ID, so group bindings keep pointing at it.
+func fixDuplicateIDs(layout *models.ControllerLayout) []Change {
+	used := make(map[string]bool)
+	for _, group := range layout.ViewGroups {
+		used[group.ID] = true
+		for _, btn := range group.ViewData.ButtonList {
+			used[btn.ID] = true
+		}
+		for _, dir := range group.ViewData.DirectionList {
+			used[dir.ID] = true
+		}
+	}
+	var changes []Change
+	seen := make(map[string]bool)
+	rename := func(id *string) {
+		if !seen[*id] {
+			seen[*id] = true
+			return
+		}
+		next := *id
+		for n := 2; used[next]; n++ {
+			next = fmt.Sprintf("%s-%d", *id, n)
+		}
+		changes = append(changes, Change{
+			Fixer:     FixDuplicateIDs,
+			ElementID: *id,
+			Message:   fmt.Sprintf("duplicate id %q renamed to %q", *id, next),
+		})
+		used[next], seen[next] = true, true
+		*id = next
+	}
+	for gi := range layout.ViewGroups {
+		group := &layout.ViewGroups[gi]
+		rename(&group.ID)
+		for i := range group.ViewData.ButtonList {
+			rename(&group.ViewData.ButtonList[i].ID)
+		}
+		for i := range group.ViewData.DirectionList {
+			rename(&group.ViewData.DirectionList[i].ID)
+		}
+	}
+	return changes
+}
+
+// fixEmptyGroups removes view groups without buttons or directions, along
+// with the bindings that toggled them.
+func fixEmptyGroups(layout *models.ControllerLayout) []Change {
+	var changes []Change
+	dropped := make(map[string]bool)
+	layout.ViewGroups = slices.DeleteFunc(layout.ViewGroups, func(group models.ViewGroup) bool {
+		if len(group.ViewData.ButtonList) > 0 || len(group.ViewData.DirectionList) > 0 {
+			return false
+		}
+		changes = append(changes, Change{
+			Fixer:     FixEmptyGroups,
+			ElementID: group.ID,
+			Message:   fmt.Sprintf("removed empty view group %q", group.Name),
+		})
+		dropped[group.ID] = true
+		return true
+	})
+	if len(dropped) == 0 {
+		return changes
+	}
+	for gi := range layout.ViewGroups {
+		for i := range layout.ViewGroups[gi].ViewData.ButtonList {
+			btn := &layout.ViewGroups[gi].ViewData.ButtonList[i]
+			binds := btn.Event.PressEvent.BindViewGroup
+			kept := slices.DeleteFunc(slices.Clone(binds), func(id string) bool { return dropped[id] })
+			if len(kept) != len(binds) {
+				changes = append(changes, Change{
+					Fixer:     FixEmptyGroups,
+					ElementID: btn.ID,
+					Message:   "removed bindings to deleted view groups",
+				})
+				btn.Event.PressEvent.BindViewGroup = kept
+			}
+		}
+	}
+	return changes
+}
+
+// fixUnknownStyles points elements with undefined styles at buttonStyle or
+// directionStyle, defaulting to the layout's first style of each kind.
+// Elements are left alone when the layout defines no style of their kind.
+func fixUnknownStyles(layout *models.ControllerLayout, buttonStyle, directionStyle string) ([]Change, error) {
+	buttonStyles := make(map[string]bool)
+	for _, s := range layout.ButtonStyles {
+		buttonStyles[s.Name] = true
+	}
+	directionStyles := make(map[string]bool)
+	for _, s := range layout.DirectionStyles {
+		directionStyles[s.Name] = true
+	}
+	if buttonStyle == "" && len(layout.ButtonStyles) > 0 {
+		buttonStyle = layout.ButtonStyles[0].Name
+	} else if buttonStyle != "" && !buttonStyles[buttonStyle] {
+		return nil, fmt.Errorf("layout has no button style %q", buttonStyle)
+	}
+	if directionStyle == "" && len(layout.DirectionStyles) > 0 {
+		directionStyle = layout.DirectionStyles[0].Name
+	} else if directionStyle != "" && !directionStyles[directionStyle] {
+		return nil, fmt.Errorf("layout has no direction style %q", directionStyle)
+	}
+
+	var changes []Change
+	for gi := range layout.ViewGroups {
+		group := &layout.ViewGroups[gi]
+		for i := range group.ViewData.ButtonList {
+			btn := &group.ViewData.ButtonList[i]
+			if !buttonStyles[btn.Style] && buttonStyle != "" {
+				changes = append(changes, Change{
+					Fixer:     FixUnknownStyles,
+					ElementID: btn.ID,
+					Message:   fmt.Sprintf("style %q -> %q", btn.Style, buttonStyle),
+				})
+				btn.Style = buttonStyle
+			}
+		}
+		for i := range group.ViewData.DirectionList {
+			dir := &group.ViewData.DirectionList[i]
+			if !directionStyles[dir.Style] && directionStyle != "" {
+				changes = append(changes, Change{
+					Fixer:     FixUnknownStyles,
+					ElementID: dir.ID,
+					Message:   fmt.Sprintf("style %q -> %q", dir.Style, directionStyle),
+				})
+				dir.Style = directionStyle
+			}
+		}
+	}
+	return changes, nil
+}
+
+// fixDuplicateKeycodes drops repeated keycodes from each button, keeping
+// the first occurrence.
+func fixDuplicateKeycodes(layout *models.ControllerLayout) []Change {
+	var changes []Change
+	for gi := range layout.ViewGroups {
+		for i := range layout.ViewGroups[gi].ViewData.ButtonList {
+			btn := &layout.ViewGroups[gi].ViewData.ButtonList[i]
+			codes := btn.Event.PressEvent.OutputKeycodes
+			var kept []int
+			for _, k := range codes {
+				if !slices.Contains(kept, k) {
+					kept = append(kept, k)
+				}
+			}
+			if len(kept) != len(codes) {
+				changes = append(changes, Change{
+					Fixer:     FixDuplicateKeycodes,
+					ElementID: btn.ID,
+					Message:   fmt.Sprintf("keycodes %v -> %v", codes, kept),
+				})
+				btn.Event.PressEvent.OutputKeycodes = kept
+			}
+		}
+	}
+	return changes
+}
+
+// fixClampPositions moves off-screen elements back so they fit on the phone
+// reference screen.
+func fixClampPositions(layout *models.ControllerLayout) []Change {
+	screen := models.DeviceScreens[models.DevicePhone]
+	var changes []Change
+	clamp := func(id string, b *models.BaseInfo) {
+		if !outOfBounds(b.XPosition, b.YPosition) {
+			return
+		}
+		_, _, w, h := b.Rect(screen.Width, screen.Height)
+		maxX := models.LayoutScale - int(w*models.LayoutScale/screen.Width)
+		maxY := models.LayoutScale - int(h*models.LayoutScale/screen.Height)
+		x := min(max(b.XPosition, 0), max(maxX, 0))
+		y := min(max(b.YPosition, 0), max(maxY, 0))
+		changes = append(changes, Change{
+			Fixer:     FixClampPositions,
+			ElementID: id,
+			Message:   fmt.Sprintf("position (%d, %d) -> (%d, %d)", b.XPosition, b.YPosition, x, y),
+		})
+		b.XPosition, b.YPosition = x, y
+	}
+	for gi := range layout.ViewGroups {
+		group := &layout.ViewGroups[gi]
+		for i := range group.ViewData.ButtonList {
+			clamp(group.ViewData.ButtonList[i].ID, &group.ViewData.ButtonList[i].BaseInfo)
+		}
+		for i := range group.ViewData.DirectionList {
+			clamp(group.ViewData.DirectionList[i].ID, &group.ViewData.DirectionList[i].BaseInfo)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+func buttonIDs(layout *models.ControllerLayout) []string {
+	var ids []string
+	for _, g := range layout.ViewGroups {
+		ids = append(ids, g.ID)
+		for _, b := range g.ViewData.ButtonList {
+			ids = append(ids, b.ID)
+		}
+	}
+	return ids
+}
+
+func TestFixDuplicateIDs(t *testing.T) {
+	layout := &models.ControllerLayout{ViewGroups: []models.ViewGroup{
+		group("main", true, absButton("a", 0, 0), absButton("a", 100, 0), absButton("a-2", 200, 0), absButton("a", 300, 0)),
+		group("main", false, absButton("b", 0, 100)),
+	}}
+	fixed, changes, err := Fix(layout, FixOptions{Fixers: []string{FixDuplicateIDs}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The existing a-2 is kept, so the repeats skip past it.
+	want := []string{"main", "a", "a-3", "a-2", "a-4", "main-2", "b"}
+	if got := buttonIDs(fixed); !slices.Equal(got, want) {
+		t.Errorf("ids = %v, want %v", got, want)
+	}
+	if len(changes) != 3 {
+		t.Errorf("changes = %v, want 3", changes)
+	}
+	if got := buttonIDs(layout); got[2] != "a" {
+		t.Errorf("Fix modified the input layout: %v", got)
+	}
+}
+
+func TestFixEmptyGroups(t *testing.T) {
+	layout := &models.ControllerLayout{ViewGroups: []models.ViewGroup{
+		group("main", true, absButton("toggle", 0, 0, "empty", "extra"), absButton("plain", 100, 0, "extra")),
+		group("empty", false),
+		group("extra", false, absButton("b", 0, 100, "empty")),
+	}}
+	fixed, changes, err := Fix(layout, FixOptions{Fixers: []string{FixEmptyGroups}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buttonIDs(fixed); !slices.Equal(got, []string{"main", "toggle", "plain", "extra", "b"}) {
+		t.Errorf("ids = %v", got)
+	}
+	binds := func(gi, bi int) []string {
+		return fixed.ViewGroups[gi].ViewData.ButtonList[bi].Event.PressEvent.BindViewGroup
+	}
+	if got := binds(0, 0); !slices.Equal(got, []string{"extra"}) {
+		t.Errorf("toggle binds %v, want [extra]", got)
+	}
+	if got := binds(0, 1); !slices.Equal(got, []string{"extra"}) {
+		t.Errorf("plain binds %v, want [extra]", got)
+	}
+	if got := binds(1, 0); len(got) != 0 {
+		t.Errorf("b binds %v, want none", got)
+	}
+	var elements []string
+	for _, c := range changes {
+		elements = append(elements, c.ElementID)
+	}
+	if !slices.Equal(elements, []string{"empty", "toggle", "b"}) {
+		t.Errorf("changes = %v", changes)
+	}
+}
+
+func TestFixClampPositions(t *testing.T) {
+	tests := []struct {
+		name         string
+		x, y         int
+		wantX, wantY int
+		changed      bool
+	}{
+		{name: "on screen", x: 500, y: 500, wantX: 500, wantY: 500},
+		{name: "on the edge", x: 1000, y: 0, wantX: 1000, wantY: 0},
+		{name: "negative", x: -20, y: -5, wantX: 0, wantY: 0, changed: true},
+		// 60px is 75 units of the 800px phone width and 166 of its 360px height.
+		{name: "past the right", x: 1200, y: 300, wantX: 925, wantY: 300, changed: true},
+		{name: "past the bottom", x: 100, y: 1100, wantX: 100, wantY: 834, changed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &models.ControllerLayout{ViewGroups: []models.ViewGroup{
+				group("main", true, absButton("a", tt.x, tt.y)),
+			}}
+			fixed, changes, err := Fix(layout, FixOptions{Fixers: []string{FixClampPositions}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			b := fixed.ViewGroups[0].ViewData.ButtonList[0].BaseInfo
+			if b.XPosition != tt.wantX || b.YPosition != tt.wantY {
+				t.Errorf("position = (%d, %d), want (%d, %d)", b.XPosition, b.YPosition, tt.wantX, tt.wantY)
+			}
+			if (len(changes) > 0) != tt.changed {
+				t.Errorf("changes = %v", changes)
+			}
+		})
+	}
+}
+
+func TestFixUnknownFixer(t *testing.T) {
+	layout := &models.ControllerLayout{}
+	if _, _, err := Fix(layout, FixOptions{Fixers: []string{"nope"}}); err == nil {
+		t.Error("expected an error for an unknown fixer")
+	}
+	if _, _, err := Fix(nil, FixOptions{}); err == nil {
+		t.Error("expected an error for a missing layout")
+	}
+}
@@ -0,0 +1,61 @@
+package audit
+
+import "strings"
+
+// Repo is the repository state that cross-package rules compare against.
+type Repo struct {
+	// IDs lists the controller IDs already in index.json.
+	IDs []string
+	// Similar returns the published layouts most like f, closest first, the
+	// way SimilarLayouts does; callers that audit after every edit can cache
+	// the result per fingerprint. Nil skips the similarity check.
+	Similar func(f Fingerprint, self string) []Match
+}
+
+// lookalikes folds characters that are easy to confuse in an ID.
+var lookalikes = strings.NewReplacer("0", "o", "1", "l", "i", "l", "5", "s", "-", "", "_", "")
+
+// SimilarIDs returns the existing IDs that id could be mistaken for: IDs
+// differing only in case, separators or look-alike characters, and IDs one
+// edit away. An exact match is an update, not a collision, and is skipped.
+func SimilarIDs(id string, existing []string) []string {
+	folded := lookalikes.Replace(strings.ToLower(id))
+	var similar []string
+	for _, other := range existing {
+		if other == id {
+			continue
+		}
+		otherFolded := lookalikes.Replace(strings.ToLower(other))
+		if folded == otherFolded || (len(folded) >= 4 && editDistance(folded, otherFolded) <= 1) {
+			similar = append(similar, other)
+		}
+	}
+	return similar
+}
+
+// editDistance is the Damerau-Levenshtein distance (with adjacent swaps)
+// between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
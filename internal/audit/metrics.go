@@ -0,0 +1,220 @@
+package audit
+
+import (
+	"math"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+// TargetScreen is a physical screen touch targets are measured on.
+type TargetScreen struct {
+	Name          string
+	Width, Height int // pixels
+	DPI           int
+}
+
+// TargetScreens cover the pixel densities controllers are commonly used at.
+var TargetScreens = []TargetScreen{
+	{Name: "phone", Width: 2400, Height: 1080, DPI: 400},
+	{Name: "budget phone", Width: 1600, Height: 720, DPI: 270},
+	{Name: "tablet", Width: 2560, Height: 1600, DPI: 280},
+}
+
+// TargetSize is the smallest element side on one TargetScreen.
+type TargetSize struct {
+	Screen      string  `json:"screen"`
+	DPI         int     `json:"dpi"`
+	ElementID   string  `json:"elementId"`
+	Millimetres float64 `json:"mm"`
+}
+
+// Metrics describe the size and complexity of a layout. Coverage and Density
+// count the groups shown at start on the phone reference screen; Density
+// splits that screen into thirds, rows top to bottom. ToggleDepth is the
+// longest chain of buttons that must be pressed to reveal a group.
+type Metrics struct {
+	Groups          int          `json:"groups"`
+	Buttons         int          `json:"buttons"`
+	Directions      int          `json:"directions"`
+	ButtonStyles    int          `json:"buttonStyles"`
+	DirectionStyles int          `json:"directionStyles"`
+	Keycodes        int          `json:"keycodes"`
+	Coverage        float64      `json:"coverage"`
+	Density         [3][3]int    `json:"density"`
+	SmallestTargets []TargetSize `json:"smallestTargets"`
+	ToggleDepth     int          `json:"toggleDepth"`
+}
+
+// MaxRegionButtons returns the button count of the busiest Density region.
+func (m Metrics) MaxRegionButtons() int {
+	most := 0
+	for _, row := range m.Density {
+		for _, n := range row {
+			most = max(most, n)
+		}
+	}
+	return most
+}
+
+// ComputeMetrics measures layout. A nil layout has zero metrics.
+func ComputeMetrics(layout *models.ControllerLayout) Metrics {
+	m := Metrics{SmallestTargets: []TargetSize{}}
+	if layout == nil {
+		return m
+	}
+	m.Groups = len(layout.ViewGroups)
+	m.ButtonStyles = len(layout.ButtonStyles)
+	m.DirectionStyles = len(layout.DirectionStyles)
+
+	keycodes := make(map[int]bool)
+	var elements, shown []element
+	for _, group := range layout.ViewGroups {
+		visible := group.Visibility == models.VisibilityShown
+		m.Buttons += len(group.ViewData.ButtonList)
+		m.Directions += len(group.ViewData.DirectionList)
+		for _, btn := range group.ViewData.ButtonList {
+			for _, k := range btn.Event.PressEvent.OutputKeycodes {
+				keycodes[k] = true
+			}
+			elements = append(elements, element{btn.ID, group.ID, btn.BaseInfo})
+			if visible {
+				shown = append(shown, element{btn.ID, group.ID, btn.BaseInfo})
+			}
+		}
+		for _, dir := range group.ViewData.DirectionList {
+			elements = append(elements, element{dir.ID, group.ID, dir.BaseInfo})
+		}
+	}
+	m.Keycodes = len(keycodes)
+
+	screen := models.DeviceScreens[models.DevicePhone]
+	for _, e := range shown {
+		x, y, w, h := e.info.Rect(screen.Width, screen.Height)
+		col := min(max(int(3*(x+w/2)/screen.Width), 0), 2)
+		row := min(max(int(3*(y+h/2)/screen.Height), 0), 2)
+		m.Density[row][col]++
+	}
+	m.Coverage = coverage(layout, screen)
+
+	for _, s := range TargetScreens {
+		// Absolute sizes are dp; percentages follow the screen's size in dp.
+		sw := float64(s.Width) * 160 / float64(s.DPI)
+		sh := float64(s.Height) * 160 / float64(s.DPI)
+		smallest := TargetSize{Screen: s.Name, DPI: s.DPI, Millimetres: math.Inf(1)}
+		for _, e := range elements {
+			_, _, w, h := e.info.Rect(sw, sh)
+			if mm := min(w, h) / 160 * 25.4; mm < smallest.Millimetres {
+				smallest.ElementID, smallest.Millimetres = e.id, mm
+			}
+		}
+		if smallest.ElementID != "" {
+			smallest.Millimetres = math.Round(smallest.Millimetres*10) / 10
+			m.SmallestTargets = append(m.SmallestTargets, smallest)
+		}
+	}
+
+	m.ToggleDepth = toggleDepth(layout)
+	return m
+}
+
+// coverage returns the percentage of screen covered by the elements of the
+// groups shown at start, sampled on a 1 dp grid.
+func coverage(layout *models.ControllerLayout, screen models.DeviceScreen) float64 {
+	cols, rows := int(screen.Width), int(screen.Height)
+	covered := make([]bool, cols*rows)
+	count := 0
+	mark := func(info models.BaseInfo) {
+		x, y, w, h := info.Rect(screen.Width, screen.Height)
+		for r := max(int(y), 0); r < min(int(math.Ceil(y+h)), rows); r++ {
+			for c := max(int(x), 0); c < min(int(math.Ceil(x+w)), cols); c++ {
+				if !covered[r*cols+c] {
+					covered[r*cols+c] = true
+					count++
+				}
+			}
+		}
+	}
+	for _, group := range layout.ViewGroups {
+		if group.Visibility != models.VisibilityShown {
+			continue
+		}
+		for _, btn := range group.ViewData.ButtonList {
+			mark(btn.BaseInfo)
+		}
+		for _, dir := range group.ViewData.DirectionList {
+			mark(dir.BaseInfo)
+		}
+	}
+	return math.Round(float64(count)*1000/float64(len(covered))) / 10
+}
+
+// toggleDepth returns how many presses the deepest group reachable from
+// the groups shown at start is away.
+func toggleDepth(layout *models.ControllerLayout) int {
+	deepest := 0
+	for _, d := range groupDepths(layout) {
+		deepest = max(deepest, d)
+	}
+	return deepest
+}
+
+// groupDepths walks group bindings from the groups shown at start and
+// returns how many presses away each reachable group is.
+func groupDepths(layout *models.ControllerLayout) map[string]int {
+	depth := make(map[string]int)
+	var queue []models.ViewGroup
+	byID := make(map[string]models.ViewGroup)
+	for _, group := range layout.ViewGroups {
+		byID[group.ID] = group
+		if group.Visibility == models.VisibilityShown {
+			depth[group.ID] = 0
+			queue = append(queue, group)
+		}
+	}
+	for len(queue) > 0 {
+		group := queue[0]
+		queue = queue[1:]
+		for _, btn := range group.ViewData.ButtonList {
+			for _, id := range btn.Event.PressEvent.BindViewGroup {
+				next, ok := byID[id]
+				if _, seen := depth[id]; seen || !ok {
+					continue
+				}
+				depth[id] = depth[group.ID] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return depth
+}
+
+// shownTogether returns whether two groups of layout can be on screen at
+// the same time. Both must be reachable from the groups shown at start, and
+// no button may bind one shown and one hidden at start, since pressing it
+// swaps them.
+func shownTogether(layout *models.ControllerLayout) func(a, b string) bool {
+	depth := groupDepths(layout)
+	shown := make(map[string]bool)
+	for _, group := range layout.ViewGroups {
+		shown[group.ID] = group.Visibility == models.VisibilityShown
+	}
+	type pair struct{ a, b string }
+	swapped := make(map[pair]bool)
+	for _, group := range layout.ViewGroups {
+		for _, btn := range group.ViewData.ButtonList {
+			binds := btn.Event.PressEvent.BindViewGroup
+			for _, a := range binds {
+				for _, b := range binds {
+					if shown[a] && !shown[b] {
+						swapped[pair{a, b}], swapped[pair{b, a}] = true, true
+					}
+				}
+			}
+		}
+	}
+	return func(a, b string) bool {
+		_, okA := depth[a]
+		_, okB := depth[b]
+		return okA && okB && (a == b || !swapped[pair{a, b}])
+	}
+}
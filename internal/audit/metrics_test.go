@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+func TestComputeMetrics(t *testing.T) {
+	small := absButton("small", 900, 900, "extra")
+	small.BaseInfo.AbsoluteWidth, small.BaseInfo.AbsoluteHeight = 30, 30
+	small.Event.PressEvent.OutputKeycodes = []int{29, 30}
+	a := absButton("a", 0, 0)
+	a.Event.PressEvent.OutputKeycodes = []int{29}
+	layout := &models.ControllerLayout{
+		ButtonStyles: []models.ButtonStyle{{Name: "Default"}},
+		ViewGroups: []models.ViewGroup{
+			group("main", true, a, absButton("same-place", 0, 0), small),
+			group("extra", false, absButton("hidden", 500, 500)),
+		},
+	}
+	m := ComputeMetrics(layout)
+	if m.Groups != 2 || m.Buttons != 4 || m.Directions != 0 || m.ButtonStyles != 1 || m.Keycodes != 2 {
+		t.Errorf("counts = %+v", m)
+	}
+	// 60x60 plus 30x30 of the 800x360 phone screen; the stacked buttons
+	// count once and the hidden group not at all.
+	if m.Coverage != 1.6 {
+		t.Errorf("coverage = %v, want 1.6", m.Coverage)
+	}
+	want := [3][3]int{{2, 0, 0}, {0, 0, 0}, {0, 0, 1}}
+	if m.Density != want {
+		t.Errorf("density = %v, want %v", m.Density, want)
+	}
+	if m.MaxRegionButtons() != 2 {
+		t.Errorf("max region buttons = %d, want 2", m.MaxRegionButtons())
+	}
+	if len(m.SmallestTargets) != len(TargetScreens) {
+		t.Fatalf("smallest targets = %+v", m.SmallestTargets)
+	}
+	for _, s := range m.SmallestTargets {
+		// Absolute sizes are dp: 30dp is 4.76mm on every screen.
+		if s.ElementID != "small" || s.Millimetres != 4.8 {
+			t.Errorf("%s smallest = %+v", s.Screen, s)
+		}
+	}
+	if m.ToggleDepth != 1 {
+		t.Errorf("toggle depth = %d, want 1", m.ToggleDepth)
+	}
+}
+
+func TestComputeMetricsNil(t *testing.T) {
+	m := ComputeMetrics(nil)
+	if m.Buttons != 0 || m.SmallestTargets == nil || len(m.SmallestTargets) != 0 {
+		t.Errorf("metrics = %+v", m)
+	}
+}
+
+func TestToggleDepth(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups []models.ViewGroup
+		want   int
+	}{
+		{name: "no groups"},
+		{
+			name:   "nothing shown at start",
+			groups: []models.ViewGroup{group("a", false, absButton("x", 0, 0, "b")), group("b", false)},
+		},
+		{
+			name: "chain",
+			groups: []models.ViewGroup{
+				group("a", true, absButton("x", 0, 0, "b")),
+				group("b", false, absButton("y", 0, 0, "c")),
+				group("c", false, absButton("z", 0, 0)),
+			},
+			want: 2,
+		},
+		{
+			name: "shortest path counts",
+			groups: []models.ViewGroup{
+				group("a", true, absButton("x", 0, 0, "b", "c")),
+				group("b", false, absButton("y", 0, 0, "c")),
+				group("c", false),
+			},
+			want: 1,
+		},
+		{
+			name: "cycle back to the start",
+			groups: []models.ViewGroup{
+				group("a", true, absButton("x", 0, 0, "b")),
+				group("b", false, absButton("y", 0, 0, "a")),
+			},
+			want: 1,
+		},
+		{
+			name: "unreachable and unknown groups",
+			groups: []models.ViewGroup{
+				group("a", true, absButton("x", 0, 0, "missing")),
+				group("b", false, absButton("y", 0, 0, "c")),
+				group("c", false),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toggleDepth(&models.ControllerLayout{ViewGroups: tt.groups}); got != tt.want {
+				t.Errorf("toggleDepth = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ProfileFile is the rule configuration kept in the repository root.
+const ProfileFile = "audit-rules.json"
+
+// Thresholds are the limits checked by the rules that take them. A zero
+// value falls back to DefaultThresholds.
+type Thresholds struct {
+	MaxButtons    int `json:"maxButtons,omitempty"`
+	MinButtonSize int `json:"minButtonSize,omitempty"` // dp on the phone reference screen
+	// MaxCoverage is the share of the screen, in percent, the groups shown
+	// at start may cover.
+	MaxCoverage      int `json:"maxCoverage,omitempty"`
+	MaxRegionButtons int `json:"maxRegionButtons,omitempty"` // per ninth of the screen
+	MaxToggleDepth   int `json:"maxToggleDepth,omitempty"`
+	// AllowedKeycodes restricts the keycodes buttons may send; empty allows any.
+	AllowedKeycodes []int `json:"allowedKeycodes,omitempty"`
+	// RequiredLocales lists the locales every index entry must provide a
+	// name and introduction for; empty requires none.
+	RequiredLocales []string `json:"requiredLocales,omitempty"`
+}
+
+// DefaultThresholds are used when no profile is selected.
+var DefaultThresholds = Thresholds{
+	MaxButtons:       100,
+	MinButtonSize:    MinTargetDP,
+	MaxCoverage:      70,
+	MaxRegionButtons: 12,
+	MaxToggleDepth:   3,
+}
+
+// withDefaults fills zero limits from DefaultThresholds.
+func (t Thresholds) withDefaults() Thresholds {
+	if t.MaxButtons == 0 {
+		t.MaxButtons = DefaultThresholds.MaxButtons
+	}
+	if t.MinButtonSize == 0 {
+		t.MinButtonSize = DefaultThresholds.MinButtonSize
+	}
+	if t.MaxCoverage == 0 {
+		t.MaxCoverage = DefaultThresholds.MaxCoverage
+	}
+	if t.MaxRegionButtons == 0 {
+		t.MaxRegionButtons = DefaultThresholds.MaxRegionButtons
+	}
+	if t.MaxToggleDepth == 0 {
+		t.MaxToggleDepth = DefaultThresholds.MaxToggleDepth
+	}
+	if len(t.AllowedKeycodes) == 0 {
+		t.AllowedKeycodes = DefaultThresholds.AllowedKeycodes
+	}
+	return t
+}
+
+// Profile is one channel's view of the rules: which are off, which report
+// at a different severity, and the thresholds used.
+type Profile struct {
+	Name       string              `json:"-"`
+	Disabled   []string            `json:"disabled,omitempty"`
+	Severity   map[string]Severity `json:"severity,omitempty"`
+	Thresholds Thresholds          `json:"thresholds"`
+}
+
+// ProfileSet is the content of ProfileFile. Suppressions accept findings
+// for the controllers they name under every profile.
+type ProfileSet struct {
+	Default      string              `json:"default,omitempty"`
+	Profiles     map[string]*Profile `json:"profiles"`
+	Suppressions []Suppression       `json:"suppressions,omitempty"`
+}
+
+// LoadProfiles reads ProfileFile from the repository root. A repository
+// without one gets an empty set.
+func LoadProfiles(repoRoot string) (*ProfileSet, error) {
+	data, err := os.ReadFile(filepath.Join(repoRoot, ProfileFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return &ProfileSet{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var set ProfileSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("%s: %v", ProfileFile, err)
+	}
+	if err := set.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", ProfileFile, err)
+	}
+	return &set, nil
+}
+
+// Validate checks that every profile names known rules and severities, and
+// that every suppression names a controller, a known rule and a reason.
+func (s *ProfileSet) Validate() error {
+	if s.Default != "" && s.Profiles[s.Default] == nil {
+		return fmt.Errorf("default profile %q is not defined", s.Default)
+	}
+	if err := validateSuppressions(s.Suppressions, true); err != nil {
+		return err
+	}
+	known := make(map[string]bool)
+	for _, r := range Rules {
+		known[r.ID] = true
+	}
+	for _, name := range s.Names() {
+		p := s.Profiles[name]
+		if p == nil {
+			return fmt.Errorf("profile %q is empty", name)
+		}
+		p.Name = name
+		for _, id := range p.Disabled {
+			if !known[id] {
+				return fmt.Errorf("profile %q disables unknown rule %q", name, id)
+			}
+		}
+		for id, sev := range p.Severity {
+			if !known[id] {
+				return fmt.Errorf("profile %q overrides unknown rule %q", name, id)
+			}
+			if !sev.valid() {
+				return fmt.Errorf("profile %q gives %s unknown severity %q", name, id, sev)
+			}
+		}
+		t := p.Thresholds
+		if t.MaxButtons < 0 || t.MinButtonSize < 0 || t.MaxCoverage < 0 || t.MaxRegionButtons < 0 || t.MaxToggleDepth < 0 {
+			return fmt.Errorf("profile %q has a negative threshold", name)
+		}
+		for _, locale := range t.RequiredLocales {
+			if strings.TrimSpace(locale) == "" {
+				return fmt.Errorf("profile %q requires an empty locale", name)
+			}
+		}
+	}
+	return nil
+}
+
+// Names lists the profiles in alphabetical order.
+func (s *ProfileSet) Names() []string {
+	names := make([]string, 0, len(s.Profiles))
+	for name := range s.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile returns the named profile. An empty name selects the set's
+// default, which may be nil for the built-in rules.
+func (s *ProfileSet) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = s.Default
+	}
+	if name == "" {
+		return nil, nil
+	}
+	p, ok := s.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown audit profile %q", name)
+	}
+	return p, nil
+}
+
+// Enabled reports whether rule id runs under p.
+func (p *Profile) Enabled(id string) bool {
+	if p == nil {
+		return true
+	}
+	for _, d := range p.Disabled {
+		if d == id {
+			return false
+		}
+	}
+	return true
+}
+
+// SeverityOf returns the severity rule r reports at under p.
+func (p *Profile) SeverityOf(r Rule) Severity {
+	if p != nil && p.Severity[r.ID] != "" {
+		return p.Severity[r.ID]
+	}
+	return r.Severity
+}
+
+// Limits returns p's thresholds with defaults filled in.
+func (p *Profile) Limits() Thresholds {
+	if p == nil {
+		return DefaultThresholds
+	}
+	return p.Thresholds.withDefaults()
+}
@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProfileSetValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		set     ProfileSet
+		wantErr string
+	}{
+		{name: "empty set"},
+		{
+			name: "valid profile",
+			set: ProfileSet{
+				Default: "strict",
+				Profiles: map[string]*Profile{"strict": {
+					Disabled:   []string{"layout.empty-group"},
+					Severity:   map[string]Severity{"layout.small-button": SeverityError},
+					Thresholds: Thresholds{MaxButtons: 40, RequiredLocales: []string{"en"}},
+				}},
+				Suppressions: []Suppression{{Controller: "demo", Rule: "layout.coverage", Reason: "full-screen pad"}},
+			},
+		},
+		{
+			name:    "undefined default",
+			set:     ProfileSet{Default: "missing"},
+			wantErr: `default profile "missing"`,
+		},
+		{
+			name:    "empty profile",
+			set:     ProfileSet{Profiles: map[string]*Profile{"lax": nil}},
+			wantErr: `profile "lax" is empty`,
+		},
+		{
+			name:    "unknown disabled rule",
+			set:     ProfileSet{Profiles: map[string]*Profile{"lax": {Disabled: []string{"layout.nope"}}}},
+			wantErr: `disables unknown rule "layout.nope"`,
+		},
+		{
+			name:    "unknown overridden rule",
+			set:     ProfileSet{Profiles: map[string]*Profile{"lax": {Severity: map[string]Severity{"layout.nope": SeverityInfo}}}},
+			wantErr: `overrides unknown rule "layout.nope"`,
+		},
+		{
+			name:    "unknown severity",
+			set:     ProfileSet{Profiles: map[string]*Profile{"lax": {Severity: map[string]Severity{"layout.coverage": "fatal"}}}},
+			wantErr: `unknown severity "fatal"`,
+		},
+		{
+			name:    "negative threshold",
+			set:     ProfileSet{Profiles: map[string]*Profile{"lax": {Thresholds: Thresholds{MaxCoverage: -1}}}},
+			wantErr: "negative threshold",
+		},
+		{
+			name:    "empty required locale",
+			set:     ProfileSet{Profiles: map[string]*Profile{"lax": {Thresholds: Thresholds{RequiredLocales: []string{"en", " "}}}}},
+			wantErr: "empty locale",
+		},
+		{
+			name:    "suppression without controller",
+			set:     ProfileSet{Suppressions: []Suppression{{Rule: "layout.coverage", Reason: "ok"}}},
+			wantErr: "needs a controller",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.set.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateNamesProfiles(t *testing.T) {
+	set := ProfileSet{Profiles: map[string]*Profile{"b": {}, "a": {}}}
+	if err := set.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if set.Profiles["a"].Name != "a" || set.Profiles["b"].Name != "b" {
+		t.Errorf("profile names not set: %+v", set.Profiles)
+	}
+	if got := set.Names(); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("Names() = %v, want [a b]", got)
+	}
+}
+
+func TestLimitsFillDefaults(t *testing.T) {
+	var none *Profile
+	if got := none.Limits(); got.MaxButtons != DefaultThresholds.MaxButtons {
+		t.Errorf("nil profile limits = %+v", got)
+	}
+	p := &Profile{Thresholds: Thresholds{MaxButtons: 10, RequiredLocales: []string{"ja"}}}
+	got := p.Limits()
+	if got.MaxButtons != 10 || got.MaxCoverage != DefaultThresholds.MaxCoverage || !slices.Equal(got.RequiredLocales, []string{"ja"}) {
+		t.Errorf("limits = %+v", got)
+	}
+}
+
+func TestLoadProfiles(t *testing.T) {
+	dir := t.TempDir()
+	set, err := LoadProfiles(dir)
+	if err != nil || len(set.Profiles) != 0 {
+		t.Fatalf("without %s: set = %+v, err = %v", ProfileFile, set, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ProfileFile), []byte(`{"default": "x", "profiles": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProfiles(dir); err == nil || !strings.Contains(err.Error(), ProfileFile) {
+		t.Errorf("invalid %s: err = %v", ProfileFile, err)
+	}
+}
@@ -0,0 +1,545 @@
+package audit
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/imaging"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+// Rules is the list of checks run by Run, in registration order.
+var Rules = []Rule{
+	{ID: "package.missing-layout", Severity: SeverityError, Check: checkMissingLayout},
+	{ID: "package.missing-index", Severity: SeverityError, Check: checkMissingIndex},
+	{ID: "package.invalid-id", Severity: SeverityError, Check: checkInvalidID},
+	{ID: "package.id-mismatch", Severity: SeverityError, Check: checkIDMismatch},
+	{ID: "package.id-collision", Severity: SeverityWarning, RepoCheck: checkIDCollision},
+	{ID: "package.similar-layout", Severity: SeverityWarning, RepoCheck: checkSimilarLayout},
+	{ID: "package.missing-version", Severity: SeverityWarning, Check: checkMissingVersion},
+	{ID: "package.missing-icon", Severity: SeverityWarning, Check: checkMissingIcon},
+	{ID: "package.icon-invalid", Severity: SeverityError, Check: checkIconInvalid},
+	{ID: "package.icon-size", Severity: SeverityWarning, Check: checkIconSize},
+	{ID: "package.icon-blank", Severity: SeverityWarning, Check: checkIconBlank},
+	{ID: "package.no-screenshots", Severity: SeverityWarning, Check: checkNoScreenshots},
+	{ID: "package.screenshot-invalid", Severity: SeverityError, Check: checkScreenshotInvalid},
+	{ID: "package.screenshot-orientation", Severity: SeverityWarning, Check: checkScreenshotOrientation},
+	{ID: "package.screenshot-duplicate", Severity: SeverityWarning, Check: checkScreenshotDuplicate},
+	{ID: "index.missing-locale", Severity: SeverityWarning, LimitCheck: checkMissingLocale},
+	{ID: "index.duplicate-locale", Severity: SeverityError, Check: checkDuplicateLocale},
+	{ID: "index.unknown-device", Severity: SeverityError, Check: checkUnknownDevice},
+	{ID: "index.device-mismatch", Severity: SeverityWarning, Check: checkDeviceMismatch},
+	{ID: "consistency.name", Severity: SeverityWarning, Check: checkConflict(utils.FieldName)},
+	{ID: "consistency.author", Severity: SeverityWarning, Check: checkConflict(utils.FieldAuthor)},
+	{ID: "consistency.description", Severity: SeverityInfo, Check: checkConflict(utils.FieldDescription)},
+	{ID: "consistency.version-name", Severity: SeverityWarning, Check: checkConflict(utils.FieldVersionName)},
+	{ID: "consistency.version-code", Severity: SeverityError, Check: checkConflict(utils.FieldVersionCode)},
+	{ID: "layout.unknown-style", Severity: SeverityError, Check: checkUnknownStyle},
+	{ID: "layout.duplicate-id", Severity: SeverityError, Check: checkDuplicateID},
+	{ID: "layout.out-of-bounds", Severity: SeverityWarning, Check: checkOutOfBounds},
+	{ID: "layout.unknown-bind-group", Severity: SeverityWarning, Check: checkUnknownBindGroup},
+	{ID: "layout.empty-group", Severity: SeverityInfo, Check: checkEmptyGroup},
+	{ID: "layout.too-many-buttons", Severity: SeverityWarning, LimitCheck: checkTooManyButtons},
+	{ID: "layout.small-button", Severity: SeverityWarning, LimitCheck: checkSmallButton},
+	{ID: "layout.disallowed-keycode", Severity: SeverityError, LimitCheck: checkDisallowedKeycode},
+	{ID: "layout.coverage", Severity: SeverityWarning, LimitCheck: checkCoverage},
+	{ID: "layout.dense-region", Severity: SeverityInfo, LimitCheck: checkDenseRegion},
+	{ID: "layout.toggle-depth", Severity: SeverityWarning, LimitCheck: checkToggleDepth},
+}
+
+func checkMissingLayout(pkg *utils.ParsedPackage) []Finding {
+	if pkg.Layout == nil {
+		return []Finding{{Message: "no layout found under versions/"}}
+	}
+	return nil
+}
+
+func checkMissingIndex(pkg *utils.ParsedPackage) []Finding {
+	if pkg.IndexEntry == nil {
+		return []Finding{{Message: "index.json is missing or invalid"}}
+	}
+	return nil
+}
+
+func checkInvalidID(pkg *utils.ParsedPackage) []Finding {
+	if pkg.IsUpdate {
+		return nil // published before the naming policy, or already checked
+	}
+	if err := utils.ValidateControllerID(pkg.ControllerID); err != nil {
+		return []Finding{{Message: err.Error()}}
+	}
+	return nil
+}
+
+func checkIDMismatch(pkg *utils.ParsedPackage) []Finding {
+	var findings []Finding
+	if pkg.IndexEntry != nil && pkg.IndexEntry.ID != pkg.ControllerID {
+		findings = append(findings, Finding{
+			Message: fmt.Sprintf("index.json has ID %q but the package folder is %q", pkg.IndexEntry.ID, pkg.ControllerID),
+		})
+	}
+	if pkg.Layout != nil && pkg.Layout.ID != pkg.ControllerID {
+		findings = append(findings, Finding{
+			Message: fmt.Sprintf("layout has ID %q but the package folder is %q", pkg.Layout.ID, pkg.ControllerID),
+		})
+	}
+	return findings
+}
+
+func checkIDCollision(pkg *utils.ParsedPackage, repo *Repo) []Finding {
+	var findings []Finding
+	for _, id := range SimilarIDs(pkg.ControllerID, repo.IDs) {
+		findings = append(findings, Finding{
+			Message: fmt.Sprintf("controller ID %q is easily confused with existing controller %q", pkg.ControllerID, id),
+		})
+	}
+	return findings
+}
+
+func checkMissingVersion(pkg *utils.ParsedPackage) []Finding {
+	if pkg.VersionInfo == nil {
+		return []Finding{{Message: "version.json is missing or invalid"}}
+	}
+	return nil
+}
+
+func checkMissingIcon(pkg *utils.ParsedPackage) []Finding {
+	if pkg.IconPath == "" {
+		return []Finding{{Message: "icon.png is missing"}}
+	}
+	return nil
+}
+
+func checkIconInvalid(pkg *utils.ParsedPackage) []Finding {
+	if pkg.IconPath == "" {
+		return nil
+	}
+	info, _, err := pkg.Image(pkg.IconPath)
+	if err != nil {
+		return []Finding{{Message: fmt.Sprintf("icon.png: %v", err)}}
+	}
+	if info.Format != "png" {
+		return []Finding{{Message: fmt.Sprintf("icon.png is a %s image, not a PNG", info.Format)}}
+	}
+	return nil
+}
+
+func checkIconSize(pkg *utils.ParsedPackage) []Finding {
+	if pkg.IconPath == "" {
+		return nil
+	}
+	info, _, err := pkg.Image(pkg.IconPath)
+	if err != nil {
+		return nil
+	}
+	var findings []Finding
+	for _, p := range imaging.IconProblems(info) {
+		findings = append(findings, Finding{Message: p})
+	}
+	return findings
+}
+
+func checkIconBlank(pkg *utils.ParsedPackage) []Finding {
+	if pkg.IconPath == "" {
+		return nil
+	}
+	info, _, err := pkg.Image(pkg.IconPath)
+	switch {
+	case err != nil:
+		return nil
+	case info.FullyTransparent:
+		return []Finding{{Message: "icon is fully transparent"}}
+	case info.SingleColor:
+		return []Finding{{Message: "icon is a single solid color"}}
+	}
+	return nil
+}
+
+func checkNoScreenshots(pkg *utils.ParsedPackage) []Finding {
+	if len(pkg.Screenshots) == 0 {
+		return []Finding{{Message: "package has no screenshots"}}
+	}
+	return nil
+}
+
+func checkMissingLocale(pkg *utils.ParsedPackage, limits Thresholds) []Finding {
+	if pkg.IndexEntry == nil {
+		return nil
+	}
+	var findings []Finding
+	for _, locale := range limits.RequiredLocales {
+		l, ok := pkg.IndexEntry.Localized(locale)
+		switch {
+		case !ok:
+			findings = append(findings, Finding{Message: fmt.Sprintf("no name or introduction for locale %q", locale)})
+		case strings.TrimSpace(l.Name) == "":
+			findings = append(findings, Finding{Message: fmt.Sprintf("name for locale %q is empty", locale)})
+		case strings.TrimSpace(l.Introduction) == "":
+			findings = append(findings, Finding{Message: fmt.Sprintf("introduction for locale %q is empty", locale)})
+		}
+	}
+	return findings
+}
+
+func checkDuplicateLocale(pkg *utils.ParsedPackage) []Finding {
+	if pkg.IndexEntry == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var findings []Finding
+	for _, l := range pkg.IndexEntry.Locales() {
+		if seen[l.Locale] {
+			findings = append(findings, Finding{Message: fmt.Sprintf("locale %q is given more than once", l.Locale)})
+		}
+		seen[l.Locale] = true
+	}
+	return findings
+}
+
+func checkUnknownDevice(pkg *utils.ParsedPackage) []Finding {
+	if pkg.IndexEntry == nil {
+		return nil
+	}
+	var findings []Finding
+	if len(pkg.IndexEntry.Device) == 0 {
+		findings = append(findings, Finding{Severity: SeverityWarning, Message: "no device types listed"})
+	}
+	for _, d := range pkg.IndexEntry.Device {
+		if !d.Valid() {
+			findings = append(findings, Finding{Message: fmt.Sprintf("unknown device type %d", int(d))})
+		}
+	}
+	return findings
+}
+
+func checkDeviceMismatch(pkg *utils.ParsedPackage) []Finding {
+	if pkg.IndexEntry == nil || pkg.Layout == nil {
+		return nil
+	}
+	declared := make(map[models.Device]bool)
+	for _, d := range pkg.IndexEntry.Device {
+		declared[d] = true
+	}
+	var findings []Finding
+	for _, s := range SuggestDevices(pkg.Layout) {
+		if declared[s.Device] && !s.Suitable {
+			findings = append(findings, Finding{
+				Message: fmt.Sprintf("listed for %s but %s", s.Name, strings.Join(s.Reasons, "; ")),
+			})
+		}
+	}
+	return findings
+}
+
+func checkScreenshotInvalid(pkg *utils.ParsedPackage) []Finding {
+	var findings []Finding
+	for _, path := range pkg.Screenshots {
+		if _, _, err := pkg.Image(path); err != nil {
+			findings = append(findings, Finding{Message: fmt.Sprintf("screenshot %s: %v", filepath.Base(path), err)})
+		}
+	}
+	return findings
+}
+
+func checkScreenshotOrientation(pkg *utils.ParsedPackage) []Finding {
+	var findings []Finding
+	for _, path := range pkg.Screenshots {
+		info, _, err := pkg.Image(path)
+		if err != nil {
+			continue
+		}
+		for _, p := range imaging.ScreenshotProblems(info) {
+			findings = append(findings, Finding{Message: fmt.Sprintf("screenshot %s %s", filepath.Base(path), p)})
+		}
+	}
+	return findings
+}
+
+func checkScreenshotDuplicate(pkg *utils.ParsedPackage) []Finding {
+	var names []string
+	var hashes []uint64
+	var findings []Finding
+	for _, path := range pkg.Screenshots {
+		_, img, err := pkg.Image(path)
+		if err != nil {
+			continue
+		}
+		h := imaging.Hash(img)
+		for i, prev := range hashes {
+			if imaging.HashDistance(h, prev) <= imaging.DuplicateDistance {
+				findings = append(findings, Finding{
+					Message: fmt.Sprintf("screenshot %s looks the same as %s", filepath.Base(path), names[i]),
+				})
+				break
+			}
+		}
+		names = append(names, filepath.Base(path))
+		hashes = append(hashes, h)
+	}
+	return findings
+}
+
+// checkConflict reports when the sources of a package fact disagree. The
+// reviewer settles it by picking a source, see utils.ParsedPackage.Resolve.
+func checkConflict(field string) func(pkg *utils.ParsedPackage) []Finding {
+	return func(pkg *utils.ParsedPackage) []Finding {
+		for _, c := range pkg.Conflicts() {
+			if c.Field != field {
+				continue
+			}
+			values := make([]string, len(c.Values))
+			for i, v := range c.Values {
+				values[i] = fmt.Sprintf("%s %q", v.Source, v.Value)
+			}
+			return []Finding{{
+				Message: fmt.Sprintf("%s differs: %s", field, strings.Join(values, ", ")),
+				Values:  c.Values,
+			}}
+		}
+		return nil
+	}
+}
+
+func checkUnknownStyle(pkg *utils.ParsedPackage) []Finding {
+	if pkg.Layout == nil {
+		return nil
+	}
+	buttonStyles := make(map[string]bool)
+	for _, s := range pkg.Layout.ButtonStyles {
+		buttonStyles[s.Name] = true
+	}
+	directionStyles := make(map[string]bool)
+	for _, s := range pkg.Layout.DirectionStyles {
+		directionStyles[s.Name] = true
+	}
+
+	var findings []Finding
+	for _, group := range pkg.Layout.ViewGroups {
+		for _, btn := range group.ViewData.ButtonList {
+			if !buttonStyles[btn.Style] {
+				findings = append(findings, Finding{
+					ElementID: btn.ID,
+					Message:   fmt.Sprintf("button %q uses unknown style %q", btn.ID, btn.Style),
+				})
+			}
+		}
+		for _, dir := range group.ViewData.DirectionList {
+			if !directionStyles[dir.Style] {
+				findings = append(findings, Finding{
+					ElementID: dir.ID,
+					Message:   fmt.Sprintf("direction %q uses unknown style %q", dir.ID, dir.Style),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func checkDuplicateID(pkg *utils.ParsedPackage) []Finding {
+	if pkg.Layout == nil {
+		return nil
+	}
+	counts := make(map[string]int)
+	var order []string
+	add := func(id string) {
+		if counts[id] == 0 {
+			order = append(order, id)
+		}
+		counts[id]++
+	}
+	for _, group := range pkg.Layout.ViewGroups {
+		add(group.ID)
+		for _, btn := range group.ViewData.ButtonList {
+			add(btn.ID)
+		}
+		for _, dir := range group.ViewData.DirectionList {
+			add(dir.ID)
+		}
+	}
+
+	var findings []Finding
+	for _, id := range order {
+		if counts[id] > 1 {
+			findings = append(findings, Finding{
+				ElementID: id,
+				Message:   fmt.Sprintf("id %q is used by %d elements", id, counts[id]),
+			})
+		}
+	}
+	return findings
+}
+
+func checkOutOfBounds(pkg *utils.ParsedPackage) []Finding {
+	if pkg.Layout == nil {
+		return nil
+	}
+	var findings []Finding
+	for _, group := range pkg.Layout.ViewGroups {
+		for _, btn := range group.ViewData.ButtonList {
+			if outOfBounds(btn.BaseInfo.XPosition, btn.BaseInfo.YPosition) {
+				findings = append(findings, Finding{
+					ElementID: btn.ID,
+					Message:   fmt.Sprintf("button %q is positioned off screen at (%d, %d)", btn.ID, btn.BaseInfo.XPosition, btn.BaseInfo.YPosition),
+				})
+			}
+		}
+		for _, dir := range group.ViewData.DirectionList {
+			if outOfBounds(dir.BaseInfo.XPosition, dir.BaseInfo.YPosition) {
+				findings = append(findings, Finding{
+					ElementID: dir.ID,
+					Message:   fmt.Sprintf("direction %q is positioned off screen at (%d, %d)", dir.ID, dir.BaseInfo.XPosition, dir.BaseInfo.YPosition),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func checkUnknownBindGroup(pkg *utils.ParsedPackage) []Finding {
+	if pkg.Layout == nil {
+		return nil
+	}
+	groups := make(map[string]bool)
+	for _, group := range pkg.Layout.ViewGroups {
+		groups[group.ID] = true
+	}
+	var findings []Finding
+	for _, group := range pkg.Layout.ViewGroups {
+		for _, btn := range group.ViewData.ButtonList {
+			for _, bind := range btn.Event.PressEvent.BindViewGroup {
+				if !groups[bind] {
+					findings = append(findings, Finding{
+						ElementID: btn.ID,
+						Message:   fmt.Sprintf("button %q toggles unknown view group %q", btn.ID, bind),
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func checkEmptyGroup(pkg *utils.ParsedPackage) []Finding {
+	if pkg.Layout == nil {
+		return nil
+	}
+	var findings []Finding
+	for _, group := range pkg.Layout.ViewGroups {
+		if len(group.ViewData.ButtonList) == 0 && len(group.ViewData.DirectionList) == 0 {
+			findings = append(findings, Finding{
+				ElementID: group.ID,
+				Message:   fmt.Sprintf("view group %q has no buttons or directions", group.Name),
+			})
+		}
+	}
+	return findings
+}
+
+func checkTooManyButtons(pkg *utils.ParsedPackage, limits Thresholds) []Finding {
+	if pkg.Layout == nil {
+		return nil
+	}
+	count := 0
+	for _, group := range pkg.Layout.ViewGroups {
+		count += len(group.ViewData.ButtonList)
+	}
+	if count > limits.MaxButtons {
+		return []Finding{{Message: fmt.Sprintf("layout has %d buttons, more than the limit of %d", count, limits.MaxButtons)}}
+	}
+	return nil
+}
+
+func checkSmallButton(pkg *utils.ParsedPackage, limits Thresholds) []Finding {
+	if pkg.Layout == nil {
+		return nil
+	}
+	screen := models.DeviceScreens[models.DevicePhone]
+	min := float64(limits.MinButtonSize)
+	var findings []Finding
+	for _, group := range pkg.Layout.ViewGroups {
+		for _, btn := range group.ViewData.ButtonList {
+			_, _, w, h := btn.BaseInfo.Rect(screen.Width, screen.Height)
+			if w < min || h < min {
+				findings = append(findings, Finding{
+					ElementID: btn.ID,
+					Message:   fmt.Sprintf("button %q is %.0fx%.0f dp on a phone, below the minimum of %d dp", btn.ID, w, h, limits.MinButtonSize),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func checkDisallowedKeycode(pkg *utils.ParsedPackage, limits Thresholds) []Finding {
+	if pkg.Layout == nil || len(limits.AllowedKeycodes) == 0 {
+		return nil
+	}
+	allowed := make(map[int]bool)
+	for _, k := range limits.AllowedKeycodes {
+		allowed[k] = true
+	}
+	var findings []Finding
+	for _, group := range pkg.Layout.ViewGroups {
+		for _, btn := range group.ViewData.ButtonList {
+			for _, k := range btn.Event.PressEvent.OutputKeycodes {
+				if !allowed[k] {
+					findings = append(findings, Finding{
+						ElementID: btn.ID,
+						Message:   fmt.Sprintf("button %q sends keycode %d, which this profile does not allow", btn.ID, k),
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func checkCoverage(pkg *utils.ParsedPackage, limits Thresholds) []Finding {
+	if pkg.Layout == nil {
+		return nil
+	}
+	if c := coverage(pkg.Layout, models.DeviceScreens[models.DevicePhone]); c > float64(limits.MaxCoverage) {
+		return []Finding{{Message: fmt.Sprintf("controls cover %.1f%% of the screen, more than the limit of %d%%", c, limits.MaxCoverage)}}
+	}
+	return nil
+}
+
+var regionNames = [3][3]string{
+	{"top left", "top", "top right"},
+	{"left", "centre", "right"},
+	{"bottom left", "bottom", "bottom right"},
+}
+
+func checkDenseRegion(pkg *utils.ParsedPackage, limits Thresholds) []Finding {
+	if pkg.Layout == nil {
+		return nil
+	}
+	var findings []Finding
+	m := ComputeMetrics(pkg.Layout)
+	for r, row := range m.Density {
+		for c, n := range row {
+			if n > limits.MaxRegionButtons {
+				findings = append(findings, Finding{
+					Message: fmt.Sprintf("%d buttons in the %s of the screen, more than the limit of %d", n, regionNames[r][c], limits.MaxRegionButtons),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func checkToggleDepth(pkg *utils.ParsedPackage, limits Thresholds) []Finding {
+	if pkg.Layout == nil {
+		return nil
+	}
+	if d := toggleDepth(pkg.Layout); d > limits.MaxToggleDepth {
+		return []Finding{{Message: fmt.Sprintf("some view groups are %d toggles deep, more than the limit of %d", d, limits.MaxToggleDepth)}}
+	}
+	return nil
+}
+
+func outOfBounds(x, y int) bool {
+	return x < 0 || y < 0 || x > models.LayoutScale || y > models.LayoutScale
+}
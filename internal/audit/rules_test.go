@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/imaging"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+// screenshot writes a w x h PNG of diagonal stripes; shift moves them.
+func screenshot(t *testing.T, dir, name string, w, h, shift int) string {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if ((x*16/w)+(y*9/h)+shift)%3 == 0 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	path := filepath.Join(dir, name)
+	if err := imaging.WritePNG(path, img); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func messages(findings []Finding) string {
+	var msgs []string
+	for _, f := range findings {
+		msgs = append(msgs, f.Message)
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func TestCheckScreenshotDuplicate(t *testing.T) {
+	dir := t.TempDir()
+	pkg := &utils.ParsedPackage{Screenshots: []string{
+		screenshot(t, dir, "1.png", 640, 360, 0),
+		screenshot(t, dir, "2.png", 640, 360, 1),
+		screenshot(t, dir, "3.png", 320, 180, 0), // 1.png at half size
+	}}
+	got := checkScreenshotDuplicate(pkg)
+	if len(got) != 1 || !strings.Contains(got[0].Message, "3.png looks the same as 1.png") {
+		t.Errorf("findings:\n%s", messages(got))
+	}
+}
+
+func TestCheckScreenshotOrientation(t *testing.T) {
+	dir := t.TempDir()
+	pkg := &utils.ParsedPackage{Screenshots: []string{
+		screenshot(t, dir, "landscape.png", 800, 450, 0),
+		screenshot(t, dir, "portrait.png", 450, 800, 0),
+	}}
+	got := checkScreenshotOrientation(pkg)
+	if len(got) != 1 || !strings.Contains(got[0].Message, "portrait.png is portrait") {
+		t.Errorf("findings:\n%s", messages(got))
+	}
+}
+
+func TestCheckMissingLocale(t *testing.T) {
+	pkg := &utils.ParsedPackage{IndexEntry: &models.IndexEntry{Lang: "en", Name: "Demo", Introduction: "A demo"}}
+
+	// Without a profile no locale is required.
+	var none *Profile
+	if got := checkMissingLocale(pkg, none.Limits()); len(got) != 0 {
+		t.Errorf("default findings:\n%s", messages(got))
+	}
+
+	p := &Profile{Thresholds: Thresholds{RequiredLocales: []string{"zh", "en"}}}
+	got := checkMissingLocale(pkg, p.Limits())
+	if len(got) != 1 || !strings.Contains(got[0].Message, `locale "zh"`) {
+		t.Errorf("findings:\n%s", messages(got))
+	}
+}
@@ -0,0 +1,226 @@
+package audit
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+// DuplicateScore is the similarity, in percent, from which a submission is
+// reported as a likely copy of a published controller.
+const DuplicateScore = 80
+
+// minPairScore is the element similarity below which two elements are not
+// considered the same control.
+const minPairScore = 0.5
+
+// printElement is the normalized description of one button or direction.
+// styled is false when the element's style is not defined, leaving colors
+// unknown.
+type printElement struct {
+	id         string
+	direction  bool
+	x, y, w, h float64 // fractions of the phone reference screen
+	keycodes   []int
+	colors     [3]int
+	styled     bool
+}
+
+func (e printElement) equal(o printElement) bool {
+	return e.id == o.id && e.direction == o.direction &&
+		e.x == o.x && e.y == o.y && e.w == o.w && e.h == o.h &&
+		slices.Equal(e.keycodes, o.keycodes) && e.colors == o.colors && e.styled == o.styled
+}
+
+// Fingerprint captures what makes a layout recognizable regardless of its
+// IDs and names: where its controls are, how large they are, what they send
+// and how they are colored.
+type Fingerprint struct {
+	elements []printElement
+}
+
+// Equal reports whether f and g describe the same layout, so comparisons
+// made for one hold for the other.
+func (f Fingerprint) Equal(g Fingerprint) bool {
+	return slices.EqualFunc(f.elements, g.elements, printElement.equal)
+}
+
+// NewFingerprint fingerprints layout. A nil layout has an empty fingerprint.
+func NewFingerprint(layout *models.ControllerLayout) Fingerprint {
+	var f Fingerprint
+	if layout == nil {
+		return f
+	}
+	buttonStyles := make(map[string]models.ButtonStyle)
+	for _, s := range layout.ButtonStyles {
+		buttonStyles[s.Name] = s
+	}
+	directionStyles := make(map[string]models.DirectionStyle)
+	for _, s := range layout.DirectionStyles {
+		directionStyles[s.Name] = s
+	}
+	screen := models.DeviceScreens[models.DevicePhone]
+	place := func(e *printElement, info models.BaseInfo) {
+		x, y, w, h := info.Rect(screen.Width, screen.Height)
+		e.x, e.y = x/screen.Width, y/screen.Height
+		e.w, e.h = w/screen.Width, h/screen.Height
+	}
+	for _, group := range layout.ViewGroups {
+		for _, btn := range group.ViewData.ButtonList {
+			style, styled := buttonStyles[btn.Style]
+			e := printElement{
+				id:       btn.ID,
+				keycodes: slices.Clone(btn.Event.PressEvent.OutputKeycodes),
+				colors:   [3]int{style.FillColor, style.StrokeColor, style.TextColor},
+				styled:   styled,
+			}
+			slices.Sort(e.keycodes)
+			e.keycodes = slices.Compact(e.keycodes)
+			place(&e, btn.BaseInfo)
+			f.elements = append(f.elements, e)
+		}
+		for _, dir := range group.ViewData.DirectionList {
+			style, styled := directionStyles[dir.Style]
+			e := printElement{id: dir.ID, direction: true, styled: styled}
+			if style.StyleType == "ROCKER" {
+				e.colors = [3]int{style.RockerStyle.BgFillColor, style.RockerStyle.BgStrokeColor, style.RockerStyle.RockerFillColor}
+			} else {
+				e.colors = [3]int{style.ButtonStyle.FillColor, style.ButtonStyle.StrokeColor, style.ButtonStyle.TextColor}
+			}
+			place(&e, dir.BaseInfo)
+			f.elements = append(f.elements, e)
+		}
+	}
+	return f
+}
+
+// similarity scores two elements from 0 to 1. Only what both elements
+// define counts in their favour: buttons that send no keycodes share none,
+// and colors of undefined styles match nothing. Directions send no
+// keycodes, so they are scored on position, size and colors alone.
+func (e printElement) similarity(o printElement) float64 {
+	if e.direction != o.direction {
+		return 0
+	}
+	position := max(0, 1-math.Hypot(e.x-o.x, e.y-o.y)/0.1)
+	size := max(0, 1-(math.Abs(e.w-o.w)+math.Abs(e.h-o.h))/0.1)
+	colors := 0.0
+	if e.styled && o.styled {
+		for i := range e.colors {
+			if e.colors[i] == o.colors[i] {
+				colors += 1.0 / 3
+			}
+		}
+	}
+	if e.direction {
+		return (0.4*position + 0.2*size + 0.15*colors) / 0.75
+	}
+	keys := 0.0
+	if len(e.keycodes) > 0 && len(o.keycodes) > 0 {
+		shared := 0
+		for _, k := range e.keycodes {
+			if slices.Contains(o.keycodes, k) {
+				shared++
+			}
+		}
+		keys = float64(shared) / float64(len(e.keycodes)+len(o.keycodes)-shared)
+	}
+	return 0.4*position + 0.2*size + 0.25*keys + 0.15*colors
+}
+
+// Pair links an element of a submission to the published element it matches.
+type Pair struct {
+	Submitted string  `json:"submitted"`
+	Published string  `json:"published"`
+	Score     float64 `json:"score"` // percent
+}
+
+// Compare scores how alike f and g are, in percent, and pairs up their
+// matching elements. Elements are matched greedily, best pairs first;
+// unmatched elements on either side lower the score.
+func (f Fingerprint) Compare(g Fingerprint) (float64, []Pair) {
+	n := max(len(f.elements), len(g.elements))
+	if n == 0 {
+		return 0, nil
+	}
+	type candidate struct {
+		i, j  int
+		score float64
+	}
+	var candidates []candidate
+	for i, a := range f.elements {
+		for j, b := range g.elements {
+			if s := a.similarity(b); s >= minPairScore {
+				candidates = append(candidates, candidate{i, j, s})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].score > candidates[b].score })
+
+	usedF := make([]bool, len(f.elements))
+	usedG := make([]bool, len(g.elements))
+	var pairs []Pair
+	total := 0.0
+	for _, c := range candidates {
+		if usedF[c.i] || usedG[c.j] {
+			continue
+		}
+		usedF[c.i], usedG[c.j] = true, true
+		total += c.score
+		pairs = append(pairs, Pair{
+			Submitted: f.elements[c.i].id,
+			Published: g.elements[c.j].id,
+			Score:     math.Round(c.score * 100),
+		})
+	}
+	return math.Round(total / float64(n) * 100), pairs
+}
+
+// Match is a published controller compared with a submission.
+type Match struct {
+	ID    string  `json:"id"`
+	Score float64 `json:"score"` // percent
+	Pairs []Pair  `json:"pairs"`
+}
+
+// SimilarLayouts compares f with every published fingerprint except the
+// controller's own and returns the matches, closest first.
+func SimilarLayouts(f Fingerprint, published map[string]Fingerprint, self string) []Match {
+	matches := []Match{}
+	for id, g := range published {
+		if id == self {
+			continue
+		}
+		score, pairs := f.Compare(g)
+		if score > 0 {
+			matches = append(matches, Match{ID: id, Score: score, Pairs: pairs})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+func checkSimilarLayout(pkg *utils.ParsedPackage, repo *Repo) []Finding {
+	if pkg.Layout == nil || repo.Similar == nil {
+		return nil
+	}
+	var findings []Finding
+	for _, m := range repo.Similar(NewFingerprint(pkg.Layout), pkg.ControllerID) {
+		if m.Score < DuplicateScore {
+			break
+		}
+		findings = append(findings, Finding{
+			Message: fmt.Sprintf("layout is %.0f%% similar to published controller %q", m.Score, m.ID),
+		})
+	}
+	return findings
+}
@@ -0,0 +1,204 @@
+package audit
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+// styledLayout wraps groups in a layout defining the Default button style.
+func styledLayout(groups ...models.ViewGroup) *models.ControllerLayout {
+	return &models.ControllerLayout{
+		ButtonStyles: []models.ButtonStyle{{Name: "Default", FillColor: 1, StrokeColor: 2, TextColor: 3}},
+		ViewGroups:   groups,
+	}
+}
+
+func keyButton(id string, x, y int, style string, keycodes ...int) models.Button {
+	b := absButton(id, x, y)
+	b.Style = style
+	b.Event.PressEvent.OutputKeycodes = keycodes
+	return b
+}
+
+func TestFingerprintCompare(t *testing.T) {
+	published := styledLayout(group("main", true,
+		keyButton("jump", 100, 100, "Default", 62),
+		keyButton("fire", 800, 700, "Default", 29, 30),
+	))
+	tests := []struct {
+		name      string
+		layout    *models.ControllerLayout
+		want      float64
+		wantPairs int
+	}{
+		{name: "identical", layout: published, want: 100, wantPairs: 2},
+		{
+			name: "renamed copy",
+			layout: styledLayout(group("g", true,
+				keyButton("a", 100, 100, "Default", 62),
+				keyButton("b", 800, 700, "Default", 30, 29, 29),
+			)),
+			want: 100, wantPairs: 2,
+		},
+		{
+			name: "half copied",
+			layout: styledLayout(group("g", true,
+				keyButton("a", 100, 100, "Default", 62),
+				keyButton("b", 400, 100, "Default", 40),
+			)),
+			want: 50, wantPairs: 1,
+		},
+		{
+			name: "extra elements lower the score",
+			layout: styledLayout(group("g", true,
+				keyButton("a", 100, 100, "Default", 62),
+				keyButton("b", 800, 700, "Default", 29, 30),
+				keyButton("c", 400, 100, "Default", 40),
+				keyButton("d", 400, 400, "Default", 41),
+			)),
+			want: 50, wantPairs: 2,
+		},
+		{
+			name:   "nothing in common",
+			layout: styledLayout(group("g", true, keyButton("a", 500, 300, "Default", 40))),
+			want:   0,
+		},
+		{name: "empty", layout: nil, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, pairs := NewFingerprint(tt.layout).Compare(NewFingerprint(published))
+			if score != tt.want || len(pairs) != tt.wantPairs {
+				t.Errorf("score %v with %d pairs, want %v with %d", score, len(pairs), tt.want, tt.wantPairs)
+			}
+		})
+	}
+}
+
+func TestSimilarityNeedsSharedEvidence(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b models.Button
+		want float64 // percent
+	}{
+		{
+			name: "same keycodes and style",
+			a:    keyButton("a", 100, 100, "Default", 62),
+			b:    keyButton("b", 100, 100, "Default", 62),
+			want: 100,
+		},
+		{
+			name: "no keycodes on either side",
+			a:    keyButton("a", 100, 100, "Default"),
+			b:    keyButton("b", 100, 100, "Default"),
+			want: 75,
+		},
+		{
+			name: "unknown styles on both sides",
+			a:    keyButton("a", 100, 100, "Missing", 62),
+			b:    keyButton("b", 100, 100, "Gone", 62),
+			want: 85,
+		},
+		{
+			name: "no keycodes and unknown styles",
+			a:    keyButton("a", 100, 100, "Missing"),
+			b:    keyButton("b", 100, 100, ""),
+			want: 60,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFingerprint(styledLayout(group("g", true, tt.a)))
+			g := NewFingerprint(styledLayout(group("g", true, tt.b)))
+			if got, _ := f.Compare(g); got != tt.want {
+				t.Errorf("score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimilarityDirections(t *testing.T) {
+	dir := func(id string, style string) models.Direction {
+		return models.Direction{ID: id, Style: style, BaseInfo: absButton(id, 100, 600).BaseInfo}
+	}
+	layout := func(d models.Direction) *models.ControllerLayout {
+		return &models.ControllerLayout{
+			DirectionStyles: []models.DirectionStyle{{Name: "Pad"}},
+			ViewGroups: []models.ViewGroup{{
+				ID: "g", Visibility: models.VisibilityShown,
+				ViewData: models.ViewData{DirectionList: []models.Direction{d}},
+			}},
+		}
+	}
+	if got, _ := NewFingerprint(layout(dir("a", "Pad"))).Compare(NewFingerprint(layout(dir("b", "Pad")))); got != 100 {
+		t.Errorf("identical directions score %v, want 100", got)
+	}
+	if got, _ := NewFingerprint(layout(dir("a", "x"))).Compare(NewFingerprint(layout(dir("b", "y")))); got != 80 {
+		t.Errorf("directions with unknown styles score %v, want 80", got)
+	}
+}
+
+func TestSimilarLayouts(t *testing.T) {
+	copied := styledLayout(group("main", true,
+		keyButton("jump", 100, 100, "Default", 62),
+		keyButton("fire", 800, 700, "Default", 29),
+	))
+	partial := styledLayout(group("main", true, keyButton("jump", 100, 100, "Default", 62)))
+	unrelated := styledLayout(group("main", true, keyButton("x", 500, 300, "Default", 40)))
+	published := map[string]Fingerprint{
+		"copied":    NewFingerprint(copied),
+		"also-same": NewFingerprint(copied),
+		"partial":   NewFingerprint(partial),
+		"unrelated": NewFingerprint(unrelated),
+		"self":      NewFingerprint(copied),
+	}
+	matches := SimilarLayouts(NewFingerprint(copied), published, "self")
+	var ids []string
+	for _, m := range matches {
+		ids = append(ids, m.ID)
+	}
+	if want := []string{"also-same", "copied", "partial"}; !slices.Equal(ids, want) {
+		t.Fatalf("matches = %v, want %v", ids, want)
+	}
+	if matches[0].Score != 100 || matches[2].Score != 50 {
+		t.Errorf("scores = %v, %v", matches[0].Score, matches[2].Score)
+	}
+	if got := SimilarLayouts(NewFingerprint(copied), nil, ""); got == nil || len(got) != 0 {
+		t.Errorf("no published layouts: %v", got)
+	}
+}
+
+func TestFingerprintEqual(t *testing.T) {
+	layout := styledLayout(group("main", true, keyButton("a", 100, 100, "Default", 62)))
+	f := NewFingerprint(layout)
+	if !f.Equal(NewFingerprint(layout)) {
+		t.Error("fingerprints of the same layout differ")
+	}
+	moved := styledLayout(group("main", true, keyButton("a", 110, 100, "Default", 62)))
+	if f.Equal(NewFingerprint(moved)) {
+		t.Error("moving a button left the fingerprint unchanged")
+	}
+	renamedGroup := styledLayout(group("other", false, keyButton("a", 100, 100, "Default", 62)))
+	if !f.Equal(NewFingerprint(renamedGroup)) {
+		t.Error("group changes that don't affect elements changed the fingerprint")
+	}
+}
+
+func TestCheckSimilarLayout(t *testing.T) {
+	layout := styledLayout(group("main", true, keyButton("a", 100, 100, "Default", 62)))
+	pkg := &utils.ParsedPackage{ControllerID: "copy", Layout: layout}
+	calls := 0
+	repo := &Repo{Similar: func(f Fingerprint, self string) []Match {
+		calls++
+		return SimilarLayouts(f, map[string]Fingerprint{"orig": NewFingerprint(layout)}, self)
+	}}
+	if got := checkSimilarLayout(pkg, repo); len(got) != 1 || calls != 1 {
+		t.Errorf("findings = %v after %d calls", got, calls)
+	}
+	if got := checkSimilarLayout(pkg, &Repo{}); got != nil {
+		t.Errorf("without Similar: %v", got)
+	}
+}
@@ -0,0 +1,104 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// IgnoreFile lists the accepted exceptions for one controller. It lives in
+// repo_json/<id>/ next to version.json.
+const IgnoreFile = "audit-ignore.json"
+
+// Suppression accepts the findings of a rule, optionally only for one
+// element, with a justification. Controller is only used in the repository
+// rule config, where suppressions for every controller share one list.
+type Suppression struct {
+	Controller string `json:"controller,omitempty"`
+	Rule       string `json:"rule"`
+	Element    string `json:"element,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+// LoadIgnoreFile reads IgnoreFile from a controller directory. A missing
+// file means no suppressions.
+func LoadIgnoreFile(dir string) ([]Suppression, error) {
+	data, err := os.ReadFile(filepath.Join(dir, IgnoreFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []Suppression
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("%s: %v", IgnoreFile, err)
+	}
+	if err := validateSuppressions(list, false); err != nil {
+		return nil, fmt.Errorf("%s: %v", IgnoreFile, err)
+	}
+	return list, nil
+}
+
+// validateSuppressions checks that every entry names a known rule and gives
+// a reason. Entries in the repository config must also name a controller.
+func validateSuppressions(list []Suppression, needController bool) error {
+	known := make(map[string]bool)
+	for _, r := range Rules {
+		known[r.ID] = true
+	}
+	for i, s := range list {
+		switch {
+		case !known[s.Rule]:
+			return fmt.Errorf("suppression %d: unknown rule %q", i+1, s.Rule)
+		case s.Reason == "":
+			return fmt.Errorf("suppression %d: %s needs a reason", i+1, s.Rule)
+		case needController && s.Controller == "":
+			return fmt.Errorf("suppression %d: %s needs a controller", i+1, s.Rule)
+		}
+	}
+	return nil
+}
+
+// SuppressionsFor returns the repository-wide suppressions for a controller.
+func (s *ProfileSet) SuppressionsFor(id string) []Suppression {
+	var list []Suppression
+	for _, sup := range s.Suppressions {
+		if sup.Controller == id {
+			list = append(list, sup)
+		}
+	}
+	return list
+}
+
+// matches reports whether s accepts finding f of controller id.
+func (s Suppression) matches(id string, f Finding) bool {
+	if s.Controller != "" && s.Controller != id {
+		return false
+	}
+	return s.Rule == f.RuleID && (s.Element == "" || s.Element == f.ElementID)
+}
+
+// Suppress marks the findings of controller id accepted by an entry of
+// list and moves them after the findings that still need attention.
+func Suppress(findings []Finding, id string, list []Suppression) []Finding {
+	if len(list) == 0 {
+		return findings
+	}
+	for i := range findings {
+		for _, s := range list {
+			if s.matches(id, findings[i]) {
+				findings[i].Suppressed = true
+				findings[i].Justification = s.Reason
+				break
+			}
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return !findings[i].Suppressed && findings[j].Suppressed
+	})
+	return findings
+}
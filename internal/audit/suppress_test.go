@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSuppress(t *testing.T) {
+	findings := func() []Finding {
+		return []Finding{
+			{RuleID: "layout.coverage", Message: "coverage"},
+			{RuleID: "layout.small-button", ElementID: "a", Message: "small a"},
+			{RuleID: "layout.small-button", ElementID: "b", Message: "small b"},
+			{RuleID: "layout.empty-group", ElementID: "g", Message: "empty g"},
+		}
+	}
+	tests := []struct {
+		name string
+		list []Suppression
+		// want lists the messages in order, suppressed ones marked with "*".
+		want []string
+	}{
+		{
+			name: "no suppressions",
+			want: []string{"coverage", "small a", "small b", "empty g"},
+		},
+		{
+			name: "rule-wide",
+			list: []Suppression{{Rule: "layout.small-button", Reason: "ok"}},
+			want: []string{"coverage", "empty g", "*small a", "*small b"},
+		},
+		{
+			name: "one element",
+			list: []Suppression{{Rule: "layout.small-button", Element: "b", Reason: "ok"}},
+			want: []string{"coverage", "small a", "empty g", "*small b"},
+		},
+		{
+			name: "other controller",
+			list: []Suppression{{Controller: "other", Rule: "layout.coverage", Reason: "ok"}},
+			want: []string{"coverage", "small a", "small b", "empty g"},
+		},
+		{
+			name: "same controller",
+			list: []Suppression{{Controller: "demo", Rule: "layout.coverage", Reason: "ok"}},
+			want: []string{"small a", "small b", "empty g", "*coverage"},
+		},
+		{
+			name: "unknown element",
+			list: []Suppression{{Rule: "layout.empty-group", Element: "x", Reason: "ok"}},
+			want: []string{"coverage", "small a", "small b", "empty g"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Suppress(findings(), "demo", tt.list)
+			var msgs []string
+			for _, f := range got {
+				if f.Suppressed {
+					if f.Justification != "ok" {
+						t.Errorf("%s: justification = %q", f.Message, f.Justification)
+					}
+					msgs = append(msgs, "*"+f.Message)
+				} else {
+					msgs = append(msgs, f.Message)
+				}
+			}
+			if strings.Join(msgs, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("findings = %v, want %v", msgs, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuppressFirstMatchWins(t *testing.T) {
+	list := []Suppression{
+		{Rule: "layout.small-button", Element: "a", Reason: "element"},
+		{Rule: "layout.small-button", Reason: "rule"},
+	}
+	got := Suppress([]Finding{{RuleID: "layout.small-button", ElementID: "a"}}, "demo", list)
+	if got[0].Justification != "element" {
+		t.Errorf("justification = %q, want element", got[0].Justification)
+	}
+}
+
+func TestSuppressionsFor(t *testing.T) {
+	set := ProfileSet{Suppressions: []Suppression{
+		{Controller: "a", Rule: "layout.coverage", Reason: "1"},
+		{Controller: "b", Rule: "layout.coverage", Reason: "2"},
+		{Controller: "a", Rule: "layout.empty-group", Reason: "3"},
+	}}
+	got := set.SuppressionsFor("a")
+	if len(got) != 2 || got[0].Reason != "1" || got[1].Reason != "3" {
+		t.Errorf("SuppressionsFor(a) = %+v", got)
+	}
+	if got := set.SuppressionsFor("c"); got != nil {
+		t.Errorf("SuppressionsFor(c) = %+v, want none", got)
+	}
+}
+
+func TestLoadIgnoreFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string // empty means no file
+		want    int
+		wantErr string
+	}{
+		{name: "missing file"},
+		{name: "valid", content: `[{"rule": "layout.coverage", "reason": "full-screen pad"}]`, want: 1},
+		{name: "bad JSON", content: `{`, wantErr: IgnoreFile},
+		{name: "unknown rule", content: `[{"rule": "layout.nope", "reason": "x"}]`, wantErr: "unknown rule"},
+		{name: "no reason", content: `[{"rule": "layout.coverage"}]`, wantErr: "needs a reason"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.content != "" {
+				if err := os.WriteFile(filepath.Join(dir, IgnoreFile), []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			list, err := LoadIgnoreFile(dir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(list) != tt.want {
+				t.Errorf("loaded %d suppressions, want %d", len(list), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/report"
+)
+
+func runAnalytics(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("analytics", flag.ContinueOnError)
+	repo := repoFlag(fs)
+	outDir := fs.String("out", ".", "directory to write analytics.html, controllers.csv and counts.csv into")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+
+	mgr, err := openRepo(*repo)
+	if err != nil {
+		return err
+	}
+	a, err := report.NewAnalytics(mgr)
+	if err != nil {
+		return err
+	}
+	files, err := a.Write(*outDir)
+	for _, f := range files {
+		fmt.Fprintln(out, f)
+	}
+	for _, u := range a.Unreadable {
+		fmt.Fprintf(out, "%s: unreadable, left out of the counts: %s\n", u.ID, u.Error)
+	}
+	return err
+}
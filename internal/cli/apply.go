@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/audit"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/service"
+)
+
+func runApply(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("apply", flag.ContinueOnError)
+	repo := repoFlag(fs)
+	profile := profileFlag(fs)
+	reviewer := fs.String("reviewer", "", "reviewer name (required)")
+	notes := fs.String("notes", "", "reviewer notes recorded in the audit log")
+	useGit := fs.Bool("git", false, "commit the update on a new git branch")
+	force := fs.Bool("force", false, "apply even if the audit reports errors")
+	fix := fs.String("fix", "", "comma-separated layout fixers to run before publishing, or all; see the fix command")
+	yes := fs.Bool("yes", false, "accept the changes proposed by -fix without listing them first")
+	fixOptions := fixFlags(fs, fix)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one ZIP path")
+	}
+
+	sess := service.New()
+	mgr, err := sess.OpenRepo(*repo)
+	if err != nil {
+		return err
+	}
+	if *profile != "" {
+		if err := sess.SelectProfile(*profile); err != nil {
+			return err
+		}
+	}
+	if *useGit {
+		if err := mgr.EnableGit(); err != nil {
+			return err
+		}
+	}
+	pkg, err := sess.OpenZip(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	if *fix != "" {
+		// Fixes are published unseen unless the reviewer has read the list:
+		// without -yes, show what would change and stop.
+		if !*yes {
+			proposed, err := sess.ProposeFixes(fixOptions())
+			if err != nil {
+				return err
+			}
+			if len(proposed) > 0 {
+				for _, c := range proposed {
+					fmt.Fprintln(out, c)
+				}
+				return fmt.Errorf("-fix would make %d change(s); review them and rerun with -yes to publish", len(proposed))
+			}
+		}
+		changes, err := sess.ApplyFixes(fixOptions())
+		if err != nil {
+			return err
+		}
+		for _, c := range changes {
+			fmt.Fprintln(out, c)
+		}
+	}
+	s := audit.Summarize(sess.Findings())
+	if s.Errors > 0 && !*force {
+		for _, f := range sess.Findings() {
+			fmt.Fprintln(out, f)
+		}
+		return fmt.Errorf("package has %d error(s); use -force to apply anyway", s.Errors)
+	}
+	if err := sess.Apply(*reviewer, *notes); err != nil {
+		return err
+	}
+	kind := "added"
+	if pkg.IsUpdate {
+		kind = "updated"
+	}
+	fmt.Fprintf(out, "%s %s at version %d\n", pkg.ControllerID, kind, pkg.VersionCode)
+	return nil
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/repository"
+)
+
+// textsFlag collects repeated -text locale=text flags.
+type textsFlag []models.LocalizedText
+
+func (t *textsFlag) String() string {
+	parts := make([]string, len(*t))
+	for i, l := range *t {
+		parts[i] = l.Locale + "=" + l.Text
+	}
+	return strings.Join(parts, ",")
+}
+
+func (t *textsFlag) Set(v string) error {
+	locale, text, ok := strings.Cut(v, "=")
+	if !ok {
+		return fmt.Errorf("expected locale=text, got %q", v)
+	}
+	*t = append(*t, models.LocalizedText{Locale: locale, Text: text})
+	return nil
+}
+
+func runCategory(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected list, add, rename, merge or delete")
+	}
+	sub, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("category "+sub, flag.ContinueOnError)
+	repo := repoFlag(fs)
+	useGit := fs.Bool("git", false, "commit the change on a new git branch")
+	var texts textsFlag
+	if sub == "add" || sub == "rename" {
+		fs.Var(&texts, "text", "locale=text name, repeatable")
+	}
+	var reviewer, notes *string
+	if sub != "list" {
+		reviewer = fs.String("reviewer", "", "reviewer name (required)")
+		notes = fs.String("notes", "", "reason for the change")
+	}
+	reassign := -1
+	if sub == "delete" {
+		fs.IntVar(&reassign, "reassign", -1, "category that takes over the deleted category's controllers")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	ids := make([]int, fs.NArg())
+	for i, a := range fs.Args() {
+		id, err := strconv.Atoi(a)
+		if err != nil {
+			return fmt.Errorf("invalid category ID %q", a)
+		}
+		ids[i] = id
+	}
+
+	mgr, err := openRepoWithGit(*repo, *useGit && sub != "list")
+	if err != nil {
+		return err
+	}
+
+	var review repository.Review
+	if reviewer != nil {
+		review = repository.Review{Reviewer: *reviewer, Notes: *notes}
+	}
+
+	switch sub {
+	case "list":
+		usage := mgr.CategoryUsage()
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tCONTROLLERS\tNAMES")
+		for _, c := range mgr.Categories {
+			names := make([]string, len(c.Lang))
+			for i, l := range c.Lang {
+				names[i] = l.Locale + "=" + l.Text
+			}
+			fmt.Fprintf(tw, "%d\t%d\t%s\n", c.ID, usage[c.ID], strings.Join(names, ", "))
+		}
+		return tw.Flush()
+	case "add":
+		var id *int
+		if len(ids) > 0 {
+			id = &ids[0]
+		}
+		cat, err := mgr.CreateCategory(id, texts, review)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "created category %d\n", cat.ID)
+		return nil
+	case "rename":
+		if len(ids) != 1 {
+			return fmt.Errorf("expected a category ID")
+		}
+		return mgr.RenameCategory(ids[0], texts, review)
+	case "merge":
+		if len(ids) != 2 {
+			return fmt.Errorf("expected <from> <into> category IDs")
+		}
+		return mgr.MergeCategories(ids[0], ids[1], review)
+	case "delete":
+		if len(ids) != 1 {
+			return fmt.Errorf("expected a category ID")
+		}
+		var to *int
+		if reassign >= 0 {
+			to = &reassign
+		}
+		return mgr.DeleteCategory(ids[0], to, review)
+	}
+	return fmt.Errorf("unknown category command %q", sub)
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/audit"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/service"
+)
+
+func runCheck(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	repo := fs.String("repo", "", "repository root; when set the package is also compared with it")
+	profile := profileFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one ZIP path")
+	}
+
+	sess := service.New()
+	if *repo != "" {
+		if _, err := sess.OpenRepo(*repo); err != nil {
+			return err
+		}
+	}
+	if *profile != "" {
+		if err := sess.SelectProfile(*profile); err != nil {
+			return err
+		}
+	}
+	pkg, err := sess.OpenZip(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	findings := sess.Findings()
+	for _, f := range findings {
+		fmt.Fprintln(out, f)
+	}
+	s := audit.Summarize(findings)
+	fmt.Fprintf(out, "%s: %d error(s), %d warning(s), %d info", pkg.ControllerID, s.Errors, s.Warnings, s.Info)
+	if s.Suppressed > 0 {
+		fmt.Fprintf(out, ", %d suppressed", s.Suppressed)
+	}
+	fmt.Fprintln(out)
+	if s.Errors > 0 {
+		return fmt.Errorf("package has %d error(s)", s.Errors)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/repository"
+)
+
+type command struct {
+	Name  string
+	Usage string
+	Run   func(args []string, out io.Writer) error
+}
+
+var commands = []command{
+	{Name: "analytics", Usage: "analytics [-repo dir] [-out dir]  write an HTML and CSV overview of the whole repository", Run: runAnalytics},
+	{Name: "apply", Usage: "apply [-repo dir] [-profile name] -reviewer name [-notes text] [-git] [-force] [-fix names|all [-yes]] [-button-style name] [-direction-style name] <package.zip>  publish a controller ZIP to the repository", Run: runApply},
+	{Name: "category", Usage: "category list|add|rename|merge|delete [-repo dir] [-reviewer name] [-text locale=text]... [-reassign id] [ids]  manage category.json", Run: runCategory},
+	{Name: "check", Usage: "check [-repo dir] [-profile name] <package.zip>  run the audit rules against a controller ZIP", Run: runCheck},
+	{Name: "deprecate", Usage: "deprecate [-repo dir] -reviewer name -reason text [-replacement id] [-undo] [-git] <id>  mark a controller as deprecated", Run: runDeprecate},
+	{Name: "export", Usage: "export [-repo dir] [-version code] [-o file.zip] <id>  package a controller as a submission ZIP", Run: runExport},
+	{Name: "fix", Usage: "fix [-repo dir] [-profile name] [-only names] [-button-style name] [-direction-style name] <package.zip>  list the changes the layout fixers would make", Run: runFix},
+	{Name: "history", Usage: "history [-repo dir] <id>  show the git commits that touched a controller", Run: runHistory},
+	{Name: "log", Usage: "log [-repo dir] [id]  show the review audit log, optionally for one controller", Run: runLog},
+	{Name: "metrics", Usage: "metrics <package.zip>  show the size and complexity of a controller layout", Run: runMetrics},
+	{Name: "remove", Usage: "remove [-repo dir] -reviewer name [-notes text] [-purge] [-git] <id>  remove a controller from the repository", Run: runRemove},
+	{Name: "rollback", Usage: "rollback [-repo dir] -reviewer name [-notes text] [-git] <id> <versionCode>  restore a previous version as latest", Run: runRollback},
+	{Name: "rules", Usage: "rules [-repo dir] [-profile name]  list the audit rules and thresholds in effect", Run: runRules},
+	{Name: "similar", Usage: "similar [-repo dir] [-top n] [-out file.png] <package.zip>  find published controllers with a similar layout", Run: runSimilar},
+	{Name: "verify", Usage: "verify [-repo dir]  check index.json, category.json and repo_json for dangling references", Run: runVerify},
+	{Name: "reject", Usage: "reject [-repo dir] [-profile name] [-reviewer name] [-notes text] [-lang zh|en] [-out dir] <package.zip>  write feedback for a rejected package", Run: runReject},
+}
+
+// Run executes the subcommand named by args[0] and returns the process exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return 2
+	}
+	for _, c := range commands {
+		if c.Name != args[0] {
+			continue
+		}
+		if err := c.Run(args[1:], stdout); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return 2
+			}
+			fmt.Fprintf(stderr, "%s: %v\n", c.Name, err)
+			return 1
+		}
+		return 0
+	}
+	fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+	printUsage(stderr)
+	return 2
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: fcl-auditor-cli <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	sorted := append([]command(nil), commands...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for _, c := range sorted {
+		fmt.Fprintf(w, "  %s\n", c.Usage)
+	}
+}
+
+// repoFlag registers the -repo flag shared by commands that open a repository.
+func repoFlag(fs *flag.FlagSet) *string {
+	return fs.String("repo", ".", "repository root containing index.json")
+}
+
+// profileFlag registers the -profile flag selecting an audit profile from
+// the repository's audit-rules.json.
+func profileFlag(fs *flag.FlagSet) *string {
+	return fs.String("profile", "", "audit profile from audit-rules.json; the repository default when empty")
+}
+
+func openRepo(root string) (*repository.Manager, error) {
+	mgr, err := repository.NewManager(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository: %v", err)
+	}
+	return mgr, nil
+}
+
+func openRepoWithGit(root string, useGit bool) (*repository.Manager, error) {
+	mgr, err := openRepo(root)
+	if err != nil || !useGit {
+		return mgr, err
+	}
+	if err := mgr.EnableGit(); err != nil {
+		return nil, err
+	}
+	return mgr, nil
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+func runExport(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	repo := repoFlag(fs)
+	version := fs.Int("version", 0, "versionCode to export (default latest)")
+	output := fs.String("o", "", "output ZIP file (default <id>.zip)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected a controller ID")
+	}
+	id := fs.Arg(0)
+	dest := *output
+	if dest == "" {
+		dest = id + ".zip"
+	}
+
+	mgr, err := openRepo(*repo)
+	if err != nil {
+		return err
+	}
+	if err := mgr.ExportControllerZip(id, *version, dest); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "wrote %s\n", dest)
+	return nil
+}
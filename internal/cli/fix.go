@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/audit"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/service"
+)
+
+// fixFlags registers the flags choosing fixers and replacement styles. The
+// returned function builds the options once the flags are parsed; fixers is
+// "" when no fixes were requested.
+func fixFlags(fs *flag.FlagSet, fixers *string) func() audit.FixOptions {
+	buttonStyle := fs.String("button-style", "", "button style that replaces unknown ones; the layout's first when empty")
+	directionStyle := fs.String("direction-style", "", "direction style that replaces unknown ones; the layout's first when empty")
+	return func() audit.FixOptions {
+		opts := audit.FixOptions{ButtonStyle: *buttonStyle, DirectionStyle: *directionStyle}
+		if *fixers != "all" {
+			for _, name := range strings.Split(*fixers, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					opts.Fixers = append(opts.Fixers, name)
+				}
+			}
+		}
+		return opts
+	}
+}
+
+// runFix shows what the fixers would change in a package without writing
+// anything; apply -fix makes the same changes before publishing.
+func runFix(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("fix", flag.ContinueOnError)
+	repo := fs.String("repo", "", "repository root; when set the package is also compared with it")
+	profile := profileFlag(fs)
+	only := fs.String("only", "all", "comma-separated fixers to run: "+strings.Join(audit.Fixers, ", "))
+	options := fixFlags(fs, only)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one ZIP path")
+	}
+
+	sess := service.New()
+	if *repo != "" {
+		if _, err := sess.OpenRepo(*repo); err != nil {
+			return err
+		}
+	}
+	if *profile != "" {
+		if err := sess.SelectProfile(*profile); err != nil {
+			return err
+		}
+	}
+	pkg, err := sess.OpenZip(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	before := audit.Summarize(sess.Findings())
+	changes, err := sess.ApplyFixes(options())
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		fmt.Fprintln(out, c)
+	}
+	after := audit.Summarize(sess.Findings())
+	fmt.Fprintf(out, "%s: %d change(s); findings %dE/%dW/%dI -> %dE/%dW/%dI\n", pkg.ControllerID, len(changes),
+		before.Errors, before.Warnings, before.Info, after.Errors, after.Warnings, after.Info)
+	return nil
+}
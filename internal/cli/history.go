@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+func runHistory(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	repo := repoFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected a controller ID")
+	}
+
+	mgr, err := openRepo(*repo)
+	if err != nil {
+		return err
+	}
+	commits, err := mgr.ControllerHistory(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, c := range commits {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Hash[:min(len(c.Hash), 10)], c.Date.Local().Format(time.DateTime), c.Author, c.Subject)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/repository"
+)
+
+func runRemove(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("remove", flag.ContinueOnError)
+	repo := repoFlag(fs)
+	reviewer := fs.String("reviewer", "", "reviewer name (required)")
+	notes := fs.String("notes", "", "reason for the removal")
+	purge := fs.Bool("purge", false, "delete repo_json/<id> instead of archiving it")
+	useGit := fs.Bool("git", false, "commit the change on a new git branch")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected a controller ID")
+	}
+
+	mgr, err := openRepoWithGit(*repo, *useGit)
+	if err != nil {
+		return err
+	}
+	if err := mgr.RemoveController(fs.Arg(0), !*purge, repository.Review{Reviewer: *reviewer, Notes: *notes}); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%s removed\n", fs.Arg(0))
+	return nil
+}
+
+func runDeprecate(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("deprecate", flag.ContinueOnError)
+	repo := repoFlag(fs)
+	reviewer := fs.String("reviewer", "", "reviewer name (required)")
+	reason := fs.String("reason", "", "why the controller is deprecated (required)")
+	replacement := fs.String("replacement", "", "ID of the controller that replaces it")
+	undo := fs.Bool("undo", false, "clear the deprecation instead")
+	useGit := fs.Bool("git", false, "commit the change on a new git branch")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected a controller ID")
+	}
+
+	mgr, err := openRepoWithGit(*repo, *useGit)
+	if err != nil {
+		return err
+	}
+	review := repository.Review{Reviewer: *reviewer}
+	if *undo {
+		err = mgr.UndeprecateController(fs.Arg(0), review)
+	} else {
+		err = mgr.DeprecateController(fs.Arg(0), *reason, *replacement, review)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%s updated\n", fs.Arg(0))
+	return nil
+}
+
+func runVerify(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	repo := repoFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	mgr, err := openRepo(*repo)
+	if err != nil {
+		return err
+	}
+	problems := mgr.CheckConsistency()
+	for _, p := range problems {
+		fmt.Fprintln(out, p)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d consistency problem(s)", len(problems))
+	}
+	fmt.Fprintln(out, "repository is consistent")
+	return nil
+}
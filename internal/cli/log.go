@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func runLog(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("log", flag.ContinueOnError)
+	repo := repoFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	mgr, err := openRepo(*repo)
+	if err != nil {
+		return err
+	}
+	records, err := mgr.AuditLog(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tACTION\tID\tVERSION\tREVIEWER\tFINDINGS\tNOTES")
+	for _, r := range records {
+		id := r.ControllerID
+		if r.Category != nil {
+			id = fmt.Sprintf("category %d", *r.Category)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d -> %d\t%s\t%dE/%dW/%dI\t%s\n",
+			r.Timestamp.Local().Format(time.DateTime), r.Action, id,
+			r.OldVersionCode, r.NewVersionCode, r.Reviewer,
+			r.Findings.Errors, r.Findings.Warnings, r.Findings.Info,
+			strings.ReplaceAll(r.Notes, "\n", " "))
+	}
+	return tw.Flush()
+}
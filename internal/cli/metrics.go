@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/audit"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+func runMetrics(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("metrics", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one ZIP path")
+	}
+
+	pkg, err := utils.ParseControllerZip(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer pkg.Cleanup()
+	if pkg.Layout == nil {
+		return fmt.Errorf("package has no layout")
+	}
+
+	m := audit.ComputeMetrics(pkg.Layout)
+	fmt.Fprintf(out, "groups:       %d\n", m.Groups)
+	fmt.Fprintf(out, "buttons:      %d\n", m.Buttons)
+	fmt.Fprintf(out, "directions:   %d\n", m.Directions)
+	fmt.Fprintf(out, "styles:       %d button, %d direction\n", m.ButtonStyles, m.DirectionStyles)
+	fmt.Fprintf(out, "keycodes:     %d distinct\n", m.Keycodes)
+	fmt.Fprintf(out, "coverage:     %.1f%%\n", m.Coverage)
+	fmt.Fprintf(out, "toggle depth: %d\n", m.ToggleDepth)
+	fmt.Fprintln(out, "density (buttons per region):")
+	for _, row := range m.Density {
+		fmt.Fprintf(out, "  %3d %3d %3d\n", row[0], row[1], row[2])
+	}
+	fmt.Fprintf(out, "busiest region: %d buttons\n", m.MaxRegionButtons())
+	fmt.Fprintln(out, "smallest target:")
+	for _, t := range m.SmallestTargets {
+		fmt.Fprintf(out, "  %-13s %3d dpi  %5.1f mm  %s\n", t.Screen, t.DPI, t.Millimetres, t.ElementID)
+	}
+	return nil
+}
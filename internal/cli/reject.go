@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/report"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/service"
+)
+
+func runReject(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("reject", flag.ContinueOnError)
+	repo := fs.String("repo", "", "repository root; when set the rejection is recorded in its audit log")
+	profile := profileFlag(fs)
+	reviewer := fs.String("reviewer", "", "reviewer name")
+	notes := fs.String("notes", "", "reviewer notes included in the feedback")
+	lang := fs.String("lang", "", "report language ("+strings.Join(report.Languages, " or ")+"); all when empty")
+	outDir := fs.String("out", ".", "directory to write the report into")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one ZIP path")
+	}
+	if *lang != "" && !slices.Contains(report.Languages, *lang) {
+		return fmt.Errorf("unsupported language %q; use %s", *lang, strings.Join(report.Languages, " or "))
+	}
+
+	sess := service.New()
+	if *repo != "" {
+		if _, err := sess.OpenRepo(*repo); err != nil {
+			return err
+		}
+	}
+	if *profile != "" {
+		if err := sess.SelectProfile(*profile); err != nil {
+			return err
+		}
+	}
+	if _, err := sess.OpenZip(fs.Arg(0)); err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	var langs []string
+	if *lang != "" {
+		langs = []string{*lang}
+	}
+	files, err := sess.Reject(*outDir, *reviewer, *notes, langs...)
+	for _, f := range files {
+		fmt.Fprintln(out, f)
+	}
+	return err
+}
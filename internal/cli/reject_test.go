@@ -0,0 +1,14 @@
+package cli
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRejectUnsupportedLanguage(t *testing.T) {
+	err := runReject([]string{"-lang", "fr", "-out", t.TempDir(), "missing.zip"}, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), `unsupported language "fr"`) {
+		t.Errorf("err = %v, want an unsupported language error", err)
+	}
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/repository"
+)
+
+func runRollback(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("rollback", flag.ContinueOnError)
+	repo := repoFlag(fs)
+	reviewer := fs.String("reviewer", "", "reviewer name (required)")
+	notes := fs.String("notes", "", "reason for the rollback")
+	useGit := fs.Bool("git", false, "commit the rollback on a new git branch")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("expected a controller ID and a versionCode")
+	}
+	versionCode, err := strconv.Atoi(fs.Arg(1))
+	if err != nil {
+		return fmt.Errorf("invalid versionCode %q", fs.Arg(1))
+	}
+
+	mgr, err := openRepoWithGit(*repo, *useGit)
+	if err != nil {
+		return err
+	}
+	if err := mgr.Rollback(fs.Arg(0), versionCode, repository.Review{Reviewer: *reviewer, Notes: *notes}); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%s rolled back to version %d\n", fs.Arg(0), versionCode)
+	return nil
+}
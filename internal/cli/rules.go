@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/audit"
+)
+
+func runRules(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("rules", flag.ContinueOnError)
+	repo := repoFlag(fs)
+	profileName := profileFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	set, err := audit.LoadProfiles(*repo)
+	if err != nil {
+		return err
+	}
+	profile, err := set.Profile(*profileName)
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		fmt.Fprintln(out, "profile: built-in rules")
+	} else {
+		fmt.Fprintf(out, "profile: %s\n", profile.Name)
+	}
+	if names := set.Names(); len(names) > 0 {
+		fmt.Fprintf(out, "available: %v\n", names)
+	}
+
+	for _, r := range audit.Rules {
+		state := string(profile.SeverityOf(r))
+		if !profile.Enabled(r.ID) {
+			state = "off"
+		}
+		fmt.Fprintf(out, "  %-34s %s\n", r.ID, state)
+	}
+
+	limits := profile.Limits()
+	fmt.Fprintf(out, "max buttons: %d\n", limits.MaxButtons)
+	fmt.Fprintf(out, "min button size: %d dp\n", limits.MinButtonSize)
+	fmt.Fprintf(out, "max coverage: %d%%\n", limits.MaxCoverage)
+	fmt.Fprintf(out, "max buttons per region: %d\n", limits.MaxRegionButtons)
+	fmt.Fprintf(out, "max toggle depth: %d\n", limits.MaxToggleDepth)
+	if len(limits.RequiredLocales) == 0 {
+		fmt.Fprintln(out, "required locales: none")
+	} else {
+		fmt.Fprintf(out, "required locales: %v\n", limits.RequiredLocales)
+	}
+	if len(limits.AllowedKeycodes) == 0 {
+		fmt.Fprintln(out, "allowed keycodes: any")
+	} else {
+		fmt.Fprintf(out, "allowed keycodes: %v\n", limits.AllowedKeycodes)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/audit"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/service"
+)
+
+// runSimilar lists the published controllers whose layouts resemble the
+// submission and can draw it side by side with the closest one.
+func runSimilar(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("similar", flag.ContinueOnError)
+	repo := repoFlag(fs)
+	top := fs.Int("top", 5, "number of matches to list; 0 lists all")
+	output := fs.String("out", "", "write a side-by-side PNG of the submission and the closest match")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one ZIP path")
+	}
+
+	sess := service.New()
+	if _, err := sess.OpenRepo(*repo); err != nil {
+		return err
+	}
+	pkg, err := sess.OpenZip(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+	if pkg.Layout == nil {
+		return fmt.Errorf("package has no layout")
+	}
+
+	matches, err := sess.SimilarControllers(*top)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		fmt.Fprintf(out, "%s: no similar published controllers\n", pkg.ControllerID)
+		return nil
+	}
+	for _, m := range matches {
+		note := ""
+		if m.Score >= audit.DuplicateScore {
+			note = "  likely copy"
+		}
+		fmt.Fprintf(out, "%5.0f%%  %s  (%d matched elements)%s\n", m.Score, m.ID, len(m.Pairs), note)
+	}
+
+	if *output == "" {
+		return nil
+	}
+	img, match, err := sess.Comparison(matches[0].ID)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, "matched elements (submitted -> published):")
+	for _, p := range match.Pairs {
+		fmt.Fprintf(out, "  %-20s -> %-20s %3.0f%%\n", p.Submitted, p.Published, p.Score)
+	}
+	fmt.Fprintf(out, "wrote %s\n", *output)
+	return nil
+}
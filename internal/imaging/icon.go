@@ -0,0 +1,38 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+)
+
+// Icon limits for icon.png.
+const (
+	IconSize     = 256 // canonical width and height written to the repository
+	IconMinSize  = 64
+	IconMaxSize  = 1024
+	IconMaxBytes = 512 * 1024
+)
+
+// IconProblems lists the size problems of a decoded icon: aspect ratio,
+// dimensions and file size.
+func IconProblems(info *Info) []string {
+	var problems []string
+	if info.Width != info.Height {
+		problems = append(problems, fmt.Sprintf("icon is %dx%d, not square", info.Width, info.Height))
+	}
+	if info.Width < IconMinSize || info.Height < IconMinSize {
+		problems = append(problems, fmt.Sprintf("icon is %dx%d, smaller than %dx%d", info.Width, info.Height, IconMinSize, IconMinSize))
+	}
+	if info.Width > IconMaxSize || info.Height > IconMaxSize {
+		problems = append(problems, fmt.Sprintf("icon is %dx%d, larger than %dx%d", info.Width, info.Height, IconMaxSize, IconMaxSize))
+	}
+	if info.Bytes > IconMaxBytes {
+		problems = append(problems, fmt.Sprintf("icon file is %d KiB, more than %d KiB", info.Bytes/1024, IconMaxBytes/1024))
+	}
+	return problems
+}
+
+// NormalizeIcon writes a decoded icon to dst as an IconSize x IconSize PNG.
+func NormalizeIcon(img image.Image, dst string) error {
+	return WritePNG(dst, Fit(img, IconSize, IconSize))
+}
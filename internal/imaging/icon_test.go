@@ -0,0 +1,64 @@
+package imaging
+
+import (
+	"image/color"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIconProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		info Info
+		want []string // one substring per expected problem
+	}{
+		{name: "canonical", info: Info{Width: IconSize, Height: IconSize, Bytes: 1000}},
+		{name: "limits", info: Info{Width: IconMinSize, Height: IconMinSize, Bytes: IconMaxBytes}},
+		{name: "not square", info: Info{Width: 256, Height: 200}, want: []string{"not square"}},
+		{name: "too small", info: Info{Width: 32, Height: 32}, want: []string{"smaller than 64x64"}},
+		{name: "too large", info: Info{Width: 2048, Height: 2048}, want: []string{"larger than 1024x1024"}},
+		{name: "too heavy", info: Info{Width: 256, Height: 256, Bytes: IconMaxBytes + 1}, want: []string{"more than 512 KiB"}},
+		{
+			name: "everything",
+			info: Info{Width: 2048, Height: 32, Bytes: 2 * IconMaxBytes},
+			want: []string{"not square", "smaller than", "larger than", "1024 KiB"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := IconProblems(&tt.info)
+			if len(got) != len(tt.want) {
+				t.Fatalf("problems = %q, want %d", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("problem %d = %q, want it to mention %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeIcon(t *testing.T) {
+	dst := filepath.Join(t.TempDir(), "icon.png")
+	if err := NormalizeIcon(fill(600, 300, color.NRGBA{0, 128, 0, 255}), dst); err != nil {
+		t.Fatal(err)
+	}
+	info, img, err := Inspect(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Format != "png" || info.Width != IconSize || info.Height != IconSize {
+		t.Errorf("normalized icon = %+v", info)
+	}
+	if len(IconProblems(info)) != 0 {
+		t.Errorf("normalized icon has problems: %v", IconProblems(info))
+	}
+	if _, _, _, a := img.At(IconSize/2, 10).RGBA(); a != 0 {
+		t.Errorf("letterbox alpha = %d, want 0", a)
+	}
+	if r, g, _, _ := img.At(IconSize/2, IconSize/2).RGBA(); r != 0 || g>>8 != 128 {
+		t.Errorf("center = %v", img.At(IconSize/2, IconSize/2))
+	}
+}
@@ -0,0 +1,101 @@
+// Package imaging inspects and normalizes the images shipped with a
+// controller package.
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+
+	"golang.org/x/image/draw"
+)
+
+// MaxPixels is the largest image, in pixels, Inspect decodes: an 8K frame.
+// Package images are untrusted, and a small file can declare dimensions
+// that would take gigabytes to decode.
+const MaxPixels = 7680 * 4320
+
+// Info describes an image file as found on disk.
+type Info struct {
+	Format string // decoder that recognised the data: png, jpeg or gif
+	Width  int
+	Height int
+	Bytes  int64
+
+	// FullyTransparent is set when every pixel has zero alpha.
+	FullyTransparent bool
+	// SingleColor is set when every pixel has the same color.
+	SingleColor bool
+}
+
+// Inspect decodes the image at path and reports what it contains. Images
+// declaring more than MaxPixels are rejected without being decoded.
+func Inspect(path string) (*Info, image.Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot decode image: %v", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > MaxPixels/cfg.Height {
+		return nil, nil, fmt.Errorf("image is %dx%d, larger than %d pixels", cfg.Width, cfg.Height, MaxPixels)
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot decode image: %v", err)
+	}
+	b := img.Bounds()
+	info := &Info{
+		Format:           format,
+		Width:            b.Dx(),
+		Height:           b.Dy(),
+		Bytes:            int64(len(data)),
+		FullyTransparent: true,
+		SingleColor:      true,
+	}
+	first := color.NRGBAModel.Convert(img.At(b.Min.X, b.Min.Y))
+	for y := b.Min.Y; y < b.Max.Y && (info.FullyTransparent || info.SingleColor); y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A != 0 {
+				info.FullyTransparent = false
+			}
+			if c != first {
+				info.SingleColor = false
+			}
+		}
+	}
+	return info, img, nil
+}
+
+// Fit scales img to fit inside w x h, keeping its aspect ratio and centering
+// it on a transparent canvas of exactly w x h.
+func Fit(img image.Image, w, h int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	b := img.Bounds()
+	scale := min(float64(w)/float64(b.Dx()), float64(h)/float64(b.Dy()))
+	sw, sh := int(float64(b.Dx())*scale+0.5), int(float64(b.Dy())*scale+0.5)
+	x, y := (w-sw)/2, (h-sh)/2
+	draw.CatmullRom.Scale(dst, image.Rect(x, y, x+sw, y+sh), img, b, draw.Over, nil)
+	return dst
+}
+
+// WritePNG encodes img as PNG at path.
+func WritePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
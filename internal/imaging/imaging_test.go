@@ -0,0 +1,132 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func fill(w, h int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestInspect(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	mixed := fill(8, 4, red)
+	mixed.Set(7, 3, color.NRGBA{0, 0, 255, 255})
+	var jpg bytes.Buffer
+	if err := jpeg.Encode(&jpg, fill(8, 4, red), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		data         []byte
+		format       string
+		transparent  bool
+		singleColor  bool
+		wantW, wantH int
+	}{
+		{name: "png", data: encodePNG(t, mixed), format: "png", wantW: 8, wantH: 4},
+		{name: "jpeg named .png", data: jpg.Bytes(), format: "jpeg", singleColor: true, wantW: 8, wantH: 4},
+		{name: "single color", data: encodePNG(t, fill(4, 4, red)), format: "png", singleColor: true, wantW: 4, wantH: 4},
+		{name: "fully transparent", data: encodePNG(t, image.NewNRGBA(image.Rect(0, 0, 4, 4))), format: "png", transparent: true, singleColor: true, wantW: 4, wantH: 4},
+		{
+			name:        "transparent with varying color",
+			data:        encodePNG(t, func() image.Image { img := fill(4, 4, color.NRGBA{}); img.Pix[0] = 9; return img }()),
+			format:      "png",
+			transparent: true, wantW: 4, wantH: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, "icon.png", tt.data)
+			info, img, err := Inspect(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Format != tt.format || info.Width != tt.wantW || info.Height != tt.wantH || info.Bytes != int64(len(tt.data)) {
+				t.Errorf("info = %+v", info)
+			}
+			if info.FullyTransparent != tt.transparent || info.SingleColor != tt.singleColor {
+				t.Errorf("transparent = %v, single color = %v; want %v, %v", info.FullyTransparent, info.SingleColor, tt.transparent, tt.singleColor)
+			}
+			if img.Bounds().Dx() != tt.wantW {
+				t.Errorf("decoded width %d", img.Bounds().Dx())
+			}
+		})
+	}
+}
+
+// withDimensions rewrites the IHDR chunk of a PNG to declare w x h.
+func withDimensions(t *testing.T, data []byte, w, h uint32) []byte {
+	t.Helper()
+	data = bytes.Clone(data)
+	// Signature (8), chunk length (4), "IHDR" (4), then width and height.
+	if string(data[12:16]) != "IHDR" {
+		t.Fatal("unexpected PNG layout")
+	}
+	binary.BigEndian.PutUint32(data[16:], w)
+	binary.BigEndian.PutUint32(data[20:], h)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestInspectRejectsHugeDimensions(t *testing.T) {
+	small := encodePNG(t, fill(2, 2, color.White))
+	path := writeFile(t, "bomb.png", withDimensions(t, small, 100000, 100000))
+	_, _, err := Inspect(path)
+	if err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("error = %v, want a size error", err)
+	}
+	if _, _, err := Inspect(writeFile(t, "junk.png", []byte("not an image"))); err == nil {
+		t.Error("Inspect accepted junk")
+	}
+}
+
+func TestFit(t *testing.T) {
+	dst := Fit(fill(200, 100, color.White), 64, 64)
+	if dst.Bounds() != image.Rect(0, 0, 64, 64) {
+		t.Fatalf("bounds = %v", dst.Bounds())
+	}
+	// A 2:1 image fills a 64x32 band in the middle; above and below it is
+	// transparent.
+	if a := dst.NRGBAAt(32, 5).A; a != 0 {
+		t.Errorf("padding alpha = %d, want 0", a)
+	}
+	if c := dst.NRGBAAt(32, 32); c.A != 255 || c.R != 255 {
+		t.Errorf("center = %v, want opaque white", c)
+	}
+}
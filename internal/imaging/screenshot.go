@@ -0,0 +1,72 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math/bits"
+
+	"golang.org/x/image/draw"
+)
+
+// Screenshot limits. Screenshots show the controller in a landscape game.
+const (
+	ScreenshotMinWidth  = 640
+	ScreenshotMaxWidth  = 1920 // size screenshots are downscaled to
+	ScreenshotMaxHeight = 1080
+	ScreenshotMinAspect = 4.0 / 3.0
+	ScreenshotMaxAspect = 22.0 / 9.0
+)
+
+// DuplicateDistance is the largest Hash distance at which two screenshots
+// are considered the same picture.
+const DuplicateDistance = 6
+
+// ScreenshotProblems lists the orientation and size problems of a decoded
+// screenshot.
+func ScreenshotProblems(info *Info) []string {
+	var problems []string
+	if info.Height > info.Width {
+		problems = append(problems, fmt.Sprintf("is portrait (%dx%d); screenshots must be landscape", info.Width, info.Height))
+	} else if aspect := float64(info.Width) / float64(info.Height); aspect < ScreenshotMinAspect || aspect > ScreenshotMaxAspect {
+		problems = append(problems, fmt.Sprintf("has an unusual aspect ratio (%dx%d)", info.Width, info.Height))
+	}
+	if info.Width < ScreenshotMinWidth && info.Height < ScreenshotMinWidth {
+		problems = append(problems, fmt.Sprintf("is only %dx%d", info.Width, info.Height))
+	}
+	return problems
+}
+
+// Hash returns a 64-bit difference hash of img. Images that look alike have
+// hashes that differ in few bits, regardless of size or encoding.
+func Hash(img image.Image) uint64 {
+	small := image.NewGray(image.Rect(0, 0, 9, 8))
+	draw.ApproxBiLinear.Scale(small, small.Bounds(), img, img.Bounds(), draw.Src, nil)
+	var h uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			h <<= 1
+			if small.GrayAt(x, y).Y < small.GrayAt(x+1, y).Y {
+				h |= 1
+			}
+		}
+	}
+	return h
+}
+
+// HashDistance counts the bits in which two hashes differ.
+func HashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Downscale shrinks img so that it fits inside maxW x maxH. Smaller images
+// are returned unchanged.
+func Downscale(img image.Image, maxW, maxH int) image.Image {
+	b := img.Bounds()
+	if b.Dx() <= maxW && b.Dy() <= maxH {
+		return img
+	}
+	scale := min(float64(maxW)/float64(b.Dx()), float64(maxH)/float64(b.Dy()))
+	dst := image.NewNRGBA(image.Rect(0, 0, int(float64(b.Dx())*scale+0.5), int(float64(b.Dy())*scale+0.5)))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
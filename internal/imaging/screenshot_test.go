@@ -0,0 +1,109 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"strings"
+	"testing"
+)
+
+// pattern draws a 9x8 grid of gray blocks that differs with seed, scaled
+// to w x h.
+func pattern(w, h, seed int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(((x*9/w)*7 + (y*8/h)*13 + seed) % 5 * 60)
+			img.Set(x, y, color.NRGBA{v, v, v, 255})
+		}
+	}
+	return img
+}
+
+func TestScreenshotProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		w, h int
+		want []string
+	}{
+		{name: "full HD", w: 1920, h: 1080},
+		{name: "4:3 tablet", w: 1024, h: 768},
+		{name: "tall phone", w: 2400, h: 1080},
+		{name: "portrait", w: 1080, h: 1920, want: []string{"is portrait"}},
+		{name: "panorama", w: 3000, h: 1000, want: []string{"unusual aspect ratio"}},
+		{name: "square", w: 1000, h: 1000, want: []string{"unusual aspect ratio"}},
+		{name: "tiny", w: 320, h: 180, want: []string{"is only 320x180"}},
+		{name: "tiny portrait", w: 180, h: 320, want: []string{"is portrait", "is only"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ScreenshotProblems(&Info{Width: tt.w, Height: tt.h})
+			if len(got) != len(tt.want) {
+				t.Fatalf("problems = %q, want %d", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("problem %d = %q, want it to mention %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestHashFindsNearDuplicates(t *testing.T) {
+	original := pattern(960, 540, 0)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, Downscale(original, 480, 270), &jpeg.Options{Quality: 60}); err != nil {
+		t.Fatal(err)
+	}
+	reencoded, _, err := image.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := HashDistance(Hash(original), Hash(reencoded)); d > DuplicateDistance {
+		t.Errorf("downscaled JPEG copy is %d bits away, want at most %d", d, DuplicateDistance)
+	}
+	if d := HashDistance(Hash(original), Hash(pattern(960, 540, 2))); d <= DuplicateDistance {
+		t.Errorf("a different picture is only %d bits away", d)
+	}
+}
+
+func TestHashDistance(t *testing.T) {
+	if d := HashDistance(0, 0); d != 0 {
+		t.Errorf("HashDistance(0, 0) = %d", d)
+	}
+	if d := HashDistance(0b1011, 0b0010); d != 2 {
+		t.Errorf("HashDistance = %d, want 2", d)
+	}
+	if d := HashDistance(0, ^uint64(0)); d != 64 {
+		t.Errorf("HashDistance = %d, want 64", d)
+	}
+}
+
+func TestDownscale(t *testing.T) {
+	tests := []struct {
+		name         string
+		w, h         int
+		wantW, wantH int
+	}{
+		{name: "4K", w: 3840, h: 2160, wantW: 1920, wantH: 1080},
+		{name: "wide", w: 4000, h: 1000, wantW: 1920, wantH: 480},
+		{name: "tall", w: 1000, h: 2160, wantW: 500, wantH: 1080},
+		{name: "already small", w: 1280, h: 720, wantW: 1280, wantH: 720},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := image.NewNRGBA(image.Rect(0, 0, tt.w, tt.h))
+			got := Downscale(src, ScreenshotMaxWidth, ScreenshotMaxHeight)
+			if b := got.Bounds(); b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+				t.Errorf("downscaled to %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.wantW, tt.wantH)
+			}
+			if tt.w <= ScreenshotMaxWidth && tt.h <= ScreenshotMaxHeight && got != image.Image(src) {
+				t.Error("a small image was copied")
+			}
+		})
+	}
+}
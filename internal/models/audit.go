@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// AuditRecord is one line of the repository's append-only audit log.
+type AuditRecord struct {
+	Timestamp      time.Time       `json:"timestamp"`
+	Action         string          `json:"action"`
+	ControllerID   string          `json:"controllerId"`
+	Category       *int            `json:"category,omitempty"`
+	OldVersionCode int             `json:"oldVersionCode"`
+	NewVersionCode int             `json:"newVersionCode"`
+	Reviewer       string          `json:"reviewer"`
+	PackageSHA256  string          `json:"packageSha256,omitempty"`
+	Findings       FindingsSummary `json:"findings"`
+	Notes          string          `json:"notes,omitempty"`
+}
+
+type FindingsSummary struct {
+	Errors     int      `json:"errors"`
+	Warnings   int      `json:"warnings"`
+	Info       int      `json:"info"`
+	Suppressed int      `json:"suppressed,omitempty"`
+	Rules      []string `json:"rules"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "fmt"
+
+// Device is a device type listed in IndexEntry.Device. Only the values the
+// client defines are known.
+type Device int
+
+const (
+	DevicePhone Device = iota
+	DeviceTablet
+)
+
+// Devices lists every known device type in display order.
+var Devices = []Device{DevicePhone, DeviceTablet}
+
+var deviceNames = map[Device]string{
+	DevicePhone:  "phone",
+	DeviceTablet: "tablet",
+}
+
+// Valid reports whether d is a known device type.
+func (d Device) Valid() bool {
+	_, ok := deviceNames[d]
+	return ok
+}
+
+func (d Device) String() string {
+	if name, ok := deviceNames[d]; ok {
+		return name
+	}
+	return fmt.Sprintf("device(%d)", int(d))
+}
+
+// DeviceScreen is the landscape screen size of a typical device, in dp.
+type DeviceScreen struct {
+	Width  float64
+	Height float64
+}
+
+// DeviceScreens gives the reference screen used to judge a layout on each
+// device type.
+var DeviceScreens = map[Device]DeviceScreen{
+	DevicePhone:  {Width: 800, Height: 360},
+	DeviceTablet: {Width: 1280, Height: 800},
+}
@@ -0,0 +1,41 @@
+package models
+
+// LayoutScale is the unit of positions and percentage sizes: thousandths of
+// the reference screen dimension.
+const LayoutScale = 1000
+
+// Default size of rendered layout previews.
+const (
+	PreviewWidth  = 1280
+	PreviewHeight = 720
+)
+
+const (
+	SizeAbsolute    = "ABSOLUTE"
+	RefScreenWidth  = "SCREEN_WIDTH"
+	RefScreenHeight = "SCREEN_HEIGHT"
+	VisibilityShown = "VISIBLE"
+)
+
+// Rect returns the element's position and size in pixels on a screen of
+// sw x sh pixels, following the same rules as the FCL renderer.
+func (b BaseInfo) Rect(sw, sh float64) (x, y, w, h float64) {
+	x = float64(b.XPosition) * sw / LayoutScale
+	y = float64(b.YPosition) * sh / LayoutScale
+
+	if b.SizeType == SizeAbsolute {
+		return x, y, float64(b.AbsoluteWidth), float64(b.AbsoluteHeight)
+	}
+
+	if b.PercentageWidth.Reference == RefScreenWidth {
+		w = float64(b.PercentageWidth.Size) * sw / LayoutScale
+	} else {
+		w = float64(b.PercentageWidth.Size) * sh / LayoutScale
+	}
+	if b.PercentageHeight.Reference == RefScreenHeight {
+		h = float64(b.PercentageHeight.Size) * sh / LayoutScale
+	} else {
+		h = float64(b.PercentageHeight.Size) * sw / LayoutScale
+	}
+	return x, y, w, h
+}
@@ -1,27 +1,105 @@
 package models
 
 type IndexEntry struct {
-	ID           string   `json:"id"`
-	Lang         string   `json:"lang"`
-	Name         string   `json:"name"`
-	Introduction string   `json:"introduction"`
-	Device       []int    `json:"device"`
-	Categories   []int    `json:"categories"`
+	ID           string       `json:"id"`
+	Lang         string       `json:"lang"`
+	Name         string       `json:"name"`
+	Introduction string       `json:"introduction"`
+	Device       []Device     `json:"device"`
+	Categories   []int        `json:"categories"`
+	Deprecated   *Deprecation `json:"deprecated,omitempty"`
+
+	// Translations holds the name and introduction in locales other than
+	// Lang, which keeps using Name and Introduction. The repository keeps
+	// them in the auditor's sidecar rather than in index.json.
+	Translations []LocalizedEntry `json:"translations,omitempty"`
+}
+
+// LocalizedEntry is the name and introduction of a controller in one locale.
+type LocalizedEntry struct {
+	Locale       string `json:"locale"`
+	Name         string `json:"name"`
+	Introduction string `json:"introduction"`
+}
+
+// Locales returns the entry's texts in every locale, primary locale first.
+func (e IndexEntry) Locales() []LocalizedEntry {
+	locales := []LocalizedEntry{{Locale: e.Lang, Name: e.Name, Introduction: e.Introduction}}
+	locales = append(locales, e.Translations...)
+	return locales
+}
+
+// Localized returns the texts for locale, if the entry has them.
+func (e IndexEntry) Localized(locale string) (LocalizedEntry, bool) {
+	for _, l := range e.Locales() {
+		if l.Locale == locale {
+			return l, true
+		}
+	}
+	return LocalizedEntry{}, false
+}
+
+// SetLocales replaces the entry's texts. The entry for Lang becomes the
+// primary Name and Introduction; if there is none, the first one does.
+func (e *IndexEntry) SetLocales(locales []LocalizedEntry) {
+	if len(locales) == 0 {
+		return
+	}
+	primary := 0
+	for i, l := range locales {
+		if l.Locale == e.Lang {
+			primary = i
+			break
+		}
+	}
+	e.Lang = locales[primary].Locale
+	e.Name = locales[primary].Name
+	e.Introduction = locales[primary].Introduction
+	e.Translations = nil
+	for i, l := range locales {
+		if i != primary {
+			e.Translations = append(e.Translations, l)
+		}
+	}
+}
+
+// Deprecation marks a controller that is kept for existing users but should
+// no longer be offered, optionally pointing at its successor.
+type Deprecation struct {
+	Reason     string `json:"reason"`
+	ReplacedBy string `json:"replacedBy,omitempty"`
+	Date       string `json:"date"`
 }
 
 type Category struct {
-	ID   int `json:"id"`
-	Lang []struct {
-		Locale string `json:"locale"`
-		Text   string `json:"text"`
-	} `json:"lang"`
+	ID   int             `json:"id"`
+	Lang []LocalizedText `json:"lang"`
+}
+
+type LocalizedText struct {
+	Locale string `json:"locale"`
+	Text   string `json:"text"`
+}
+
+// Text returns the category name for locale, falling back to the first
+// available translation.
+func (c Category) Text(locale string) string {
+	for _, l := range c.Lang {
+		if l.Locale == locale {
+			return l.Text
+		}
+	}
+	if len(c.Lang) > 0 {
+		return c.Lang[0].Text
+	}
+	return ""
 }
 
 type RepoVersion struct {
-	Screenshot  int      `json:"screenshot"`
-	Description string   `json:"description"`
-	Author      string   `json:"author"`
-	Latest      Version  `json:"latest"`
+	Screenshot  int       `json:"screenshot"`
+	Description string    `json:"description"`
+	Author      string    `json:"author"`
+	Latest      Version   `json:"latest"`
 	History     []Version `json:"history"`
 }
 
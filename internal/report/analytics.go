@@ -0,0 +1,394 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/repository"
+)
+
+// UnmaintainedCount is how many of the least recently changed controllers
+// the analytics report lists.
+const UnmaintainedCount = 10
+
+// Count is one row of a tally, e.g. the controllers in a category.
+type Count struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// ControllerStats is what the analytics report knows about one controller.
+// LastChanged is the latest applied update in the audit log, or the last
+// git commit of its repo_json directory when the log has none; it is zero
+// when neither knows. Error is set when the controller's files could not be
+// read, in which case the version, screenshot and layout fields are empty.
+type ControllerStats struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Author       string    `json:"author"`
+	Categories   []string  `json:"categories"`
+	Devices      []string  `json:"devices"`
+	VersionName  string    `json:"versionName"`
+	VersionCode  int       `json:"versionCode"`
+	Versions     int       `json:"versions"`
+	Updates      int       `json:"updates"`
+	MeanInterval float64   `json:"meanIntervalDays"` // between logged updates; 0 with fewer than two
+	LastChanged  time.Time `json:"lastChanged"`
+	Screenshots  int       `json:"screenshots"`
+	Buttons      int       `json:"buttons"`
+	Keycodes     []int     `json:"keycodes"`
+	Deprecated   bool      `json:"deprecated"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// LoadError is a controller whose files could not be read.
+type LoadError struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// Analytics is an overview of a whole repository for its maintainers.
+type Analytics struct {
+	Generated     time.Time         `json:"generated"`
+	Controllers   []ControllerStats `json:"controllers"`
+	Deprecated    int               `json:"deprecated"`
+	ByCategory    []Count           `json:"byCategory"`
+	ByAuthor      []Count           `json:"byAuthor"`
+	ByDevice      []Count           `json:"byDevice"`
+	TopKeycodes   []Count           `json:"topKeycodes"` // controllers sending each keycode
+	NoScreenshots []string          `json:"noScreenshots"`
+	Unmaintained  []ControllerStats `json:"unmaintained"`
+	Unreadable    []LoadError       `json:"unreadable"`
+}
+
+// NewAnalytics gathers statistics over every controller in index.json and
+// its repo_json directory.
+func NewAnalytics(m *repository.Manager) (*Analytics, error) {
+	records, err := m.AuditLog("")
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[string][]time.Time)
+	for _, rec := range records {
+		if rec.Action == repository.ActionApply {
+			applied[rec.ControllerID] = append(applied[rec.ControllerID], rec.Timestamp)
+		}
+	}
+	categoryNames := make(map[int]string)
+	for _, c := range m.Categories {
+		categoryNames[c.ID] = c.Text("en")
+	}
+
+	a := &Analytics{
+		Generated:     time.Now(),
+		Controllers:   []ControllerStats{},
+		NoScreenshots: []string{},
+		Unmaintained:  []ControllerStats{},
+		Unreadable:    []LoadError{},
+	}
+	categories := make(map[string]int)
+	authors := make(map[string]int)
+	devices := make(map[string]int)
+	keycodes := make(map[string]int)
+	for _, entry := range m.Index {
+		s := ControllerStats{
+			ID:         entry.ID,
+			Name:       entry.Name,
+			Categories: []string{},
+			Devices:    []string{},
+			Keycodes:   []int{},
+			Deprecated: entry.Deprecated != nil,
+		}
+		for _, c := range entry.Categories {
+			name := categoryNames[c]
+			if name == "" {
+				name = strconv.Itoa(c)
+			}
+			s.Categories = append(s.Categories, name)
+			categories[name]++
+		}
+		for _, d := range entry.Device {
+			s.Devices = append(s.Devices, d.String())
+			devices[d.String()]++
+		}
+
+		if pkg, err := m.LoadPackage(entry.ID); err != nil {
+			s.Error = err.Error()
+			a.Unreadable = append(a.Unreadable, LoadError{ID: entry.ID, Error: s.Error})
+		} else {
+			if pkg.VersionInfo != nil {
+				s.Author = pkg.VersionInfo.Author
+				s.VersionName = pkg.VersionInfo.Latest.VersionName
+				s.Versions = len(pkg.VersionInfo.History) + 1
+			}
+			s.VersionCode = pkg.VersionCode
+			s.Screenshots = len(pkg.Screenshots)
+			if pkg.Layout != nil {
+				if s.Author == "" {
+					s.Author = pkg.Layout.Author
+				}
+				seen := make(map[int]bool)
+				for _, group := range pkg.Layout.ViewGroups {
+					s.Buttons += len(group.ViewData.ButtonList)
+					for _, btn := range group.ViewData.ButtonList {
+						for _, k := range btn.Event.PressEvent.OutputKeycodes {
+							if !seen[k] {
+								seen[k] = true
+								s.Keycodes = append(s.Keycodes, k)
+								keycodes[strconv.Itoa(k)]++
+							}
+						}
+					}
+				}
+				sort.Ints(s.Keycodes)
+			}
+		}
+		if s.Author != "" {
+			authors[s.Author]++
+		}
+
+		times := applied[entry.ID]
+		s.Updates = len(times)
+		if len(times) > 0 {
+			s.LastChanged = times[len(times)-1]
+		} else if t, err := m.LastCommitted(entry.ID); err == nil {
+			s.LastChanged = t
+		}
+		if len(times) > 1 {
+			span := times[len(times)-1].Sub(times[0]).Hours() / 24
+			s.MeanInterval = math.Round(span/float64(len(times)-1)*10) / 10
+		}
+
+		if s.Deprecated {
+			a.Deprecated++
+		}
+		if s.Screenshots == 0 && s.Error == "" {
+			a.NoScreenshots = append(a.NoScreenshots, s.ID)
+		}
+		a.Controllers = append(a.Controllers, s)
+	}
+
+	a.ByCategory = tally(categories)
+	a.ByAuthor = tally(authors)
+	a.ByDevice = tally(devices)
+	a.TopKeycodes = tally(keycodes)
+	if len(a.TopKeycodes) > 20 {
+		a.TopKeycodes = a.TopKeycodes[:20]
+	}
+
+	for _, s := range a.Controllers {
+		if !s.Deprecated {
+			a.Unmaintained = append(a.Unmaintained, s)
+		}
+	}
+	// Controllers with an unknown date go last rather than looking oldest.
+	sort.SliceStable(a.Unmaintained, func(i, j int) bool {
+		ti, tj := a.Unmaintained[i].LastChanged, a.Unmaintained[j].LastChanged
+		if ti.IsZero() || tj.IsZero() {
+			return !ti.IsZero() && tj.IsZero()
+		}
+		return ti.Before(tj)
+	})
+	if len(a.Unmaintained) > UnmaintainedCount {
+		a.Unmaintained = a.Unmaintained[:UnmaintainedCount]
+	}
+	return a, nil
+}
+
+// tally turns counts into rows, largest first.
+func tally(counts map[string]int) []Count {
+	rows := make([]Count, 0, len(counts))
+	for k, n := range counts {
+		rows = append(rows, Count{Key: k, Count: n})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Count != rows[j].Count {
+			return rows[i].Count > rows[j].Count
+		}
+		return rows[i].Key < rows[j].Key
+	})
+	return rows
+}
+
+// Write writes analytics.html, controllers.csv and counts.csv into dir and
+// returns the paths it wrote.
+func (a *Analytics) Write(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var written []string
+	html, err := a.HTML()
+	if err != nil {
+		return nil, err
+	}
+	files := []struct {
+		name  string
+		write func(w io.Writer) error
+	}{
+		{"analytics.html", func(w io.Writer) error { _, err := io.WriteString(w, html); return err }},
+		{"controllers.csv", a.WriteControllersCSV},
+		{"counts.csv", a.WriteCountsCSV},
+	}
+	for _, f := range files {
+		var buf bytes.Buffer
+		if err := f.write(&buf); err != nil {
+			return written, err
+		}
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// WriteControllersCSV writes one row per controller. The error column is
+// set for controllers whose files could not be read.
+func (a *Analytics) WriteControllersCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "name", "author", "categories", "devices", "version_name", "version_code",
+		"versions", "updates", "mean_interval_days", "last_changed", "screenshots", "buttons", "keycodes", "deprecated", "error"})
+	for _, s := range a.Controllers {
+		codes := make([]string, len(s.Keycodes))
+		for i, k := range s.Keycodes {
+			codes[i] = strconv.Itoa(k)
+		}
+		cw.Write([]string{
+			s.ID, s.Name, s.Author,
+			strings.Join(s.Categories, ";"), strings.Join(s.Devices, ";"),
+			s.VersionName, strconv.Itoa(s.VersionCode),
+			strconv.Itoa(s.Versions), strconv.Itoa(s.Updates),
+			strconv.FormatFloat(s.MeanInterval, 'f', 1, 64), formatDate(s.LastChanged),
+			strconv.Itoa(s.Screenshots), strconv.Itoa(s.Buttons),
+			strings.Join(codes, ";"), strconv.FormatBool(s.Deprecated), s.Error,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCountsCSV writes the category, author, device and keycode tallies as
+// section,key,count rows.
+func (a *Analytics) WriteCountsCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"section", "key", "count"})
+	sections := []struct {
+		name string
+		rows []Count
+	}{
+		{"category", a.ByCategory},
+		{"author", a.ByAuthor},
+		{"device", a.ByDevice},
+		{"keycode", a.TopKeycodes},
+	}
+	for _, sec := range sections {
+		for _, c := range sec.rows {
+			cw.Write([]string{sec.name, c.Key, strconv.Itoa(c.Count)})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// HTML returns the report as a self-contained page.
+func (a *Analytics) HTML() (string, error) {
+	type section struct {
+		Title string
+		Rows  []Count
+	}
+	data := struct {
+		*Analytics
+		Total    int
+		Sections []section
+	}{
+		Analytics: a,
+		Total:     len(a.Controllers),
+		Sections: []section{
+			{"Controllers per category", a.ByCategory},
+			{"Controllers per author", a.ByAuthor},
+			{"Controllers per device", a.ByDevice},
+			{"Most common keycodes", a.TopKeycodes},
+		},
+	}
+	var buf bytes.Buffer
+	if err := analyticsTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+var analyticsTemplate = template.Must(template.New("analytics").Funcs(template.FuncMap{
+	"date": formatDate,
+	"join": strings.Join,
+	"pct": func(n, total int) string {
+		if total == 0 {
+			return "0%"
+		}
+		return fmt.Sprintf("%.0f%%", float64(n)*100/float64(total))
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Repository Analytics</title>
+<style>
+body { font-family: sans-serif; max-width: 1100px; margin: 2em auto; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ddd; padding: 4px 10px; text-align: left; }
+th { background: #f2f3f4; }
+td.num { text-align: right; }
+.grid { display: grid; grid-template-columns: repeat(auto-fill, minmax(320px, 1fr)); gap: 0 24px; }
+.muted { color: #7f8c8d; }
+.error { color: #c0392b; }
+</style>
+</head>
+<body>
+<h1>Repository Analytics</h1>
+<p class="muted">Generated {{date .Generated}} · {{len .Controllers}} controllers, {{.Deprecated}} deprecated</p>
+{{if .Unreadable}}<h2 class="error">Unreadable controllers</h2>
+<p>These could not be loaded; their counts below are incomplete.</p>
+<table>
+<tr><th>ID</th><th>Error</th></tr>
+{{range .Unreadable}}<tr><td><code>{{.ID}}</code></td><td>{{.Error}}</td></tr>
+{{end}}</table>{{end}}
+<div class="grid">
+{{range .Sections}}<div>
+<h2>{{.Title}}</h2>
+{{if .Rows}}<table>
+{{range .Rows}}<tr><td>{{.Key}}</td><td class="num">{{.Count}}</td><td class="num">{{pct .Count $.Total}}</td></tr>
+{{end}}</table>{{else}}<p class="muted">None</p>{{end}}
+</div>
+{{end}}</div>
+<h2>Without screenshots</h2>
+{{if .NoScreenshots}}<p>{{range $i, $id := .NoScreenshots}}{{if $i}}, {{end}}<code>{{$id}}</code>{{end}}</p>{{else}}<p class="muted">Every controller has screenshots.</p>{{end}}
+<h2>Least recently changed</h2>
+<table>
+<tr><th>ID</th><th>Name</th><th>Author</th><th>Last changed</th><th>Updates</th></tr>
+{{range .Unmaintained}}<tr><td><code>{{.ID}}</code></td><td>{{.Name}}</td><td>{{.Author}}</td><td>{{with date .LastChanged}}{{.}}{{else}}<span class="muted">unknown</span>{{end}}</td><td class="num">{{.Updates}}</td></tr>
+{{end}}</table>
+<h2>Controllers</h2>
+<table>
+<tr><th>ID</th><th>Name</th><th>Author</th><th>Categories</th><th>Devices</th><th>Version</th><th>Versions</th><th>Updates</th><th>Days between updates</th><th>Screenshots</th><th>Buttons</th></tr>
+{{range .Controllers}}<tr><td><code>{{.ID}}</code>{{if .Deprecated}} <span class="muted">(deprecated)</span>{{end}}{{if .Error}} <span class="error">(unreadable)</span>{{end}}</td><td>{{.Name}}</td><td>{{.Author}}</td><td>{{join .Categories ", "}}</td><td>{{join .Devices ", "}}</td><td>{{.VersionName}} ({{.VersionCode}})</td><td class="num">{{.Versions}}</td><td class="num">{{.Updates}}</td><td class="num">{{if .MeanInterval}}{{.MeanInterval}}{{end}}</td><td class="num">{{.Screenshots}}</td><td class="num">{{.Buttons}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,150 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/repository"
+)
+
+func writeJSON(t *testing.T, path string, v any) {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestRepo writes a repository with a readable controller "good" and a
+// controller "broken" whose layout is not valid JSON.
+func newTestRepo(t *testing.T) *repository.Manager {
+	t.Helper()
+	root := t.TempDir()
+	writeJSON(t, filepath.Join(root, "index.json"), []models.IndexEntry{
+		{ID: "good", Lang: "en", Name: "Good", Categories: []int{}},
+		{ID: "broken", Lang: "en", Name: "Broken", Categories: []int{}},
+	})
+	writeJSON(t, filepath.Join(root, "category.json"), []models.Category{})
+	for _, id := range []string{"good", "broken"} {
+		dir := filepath.Join(root, "repo_json", id)
+		writeJSON(t, filepath.Join(dir, "version.json"), models.RepoVersion{
+			Author: "alice",
+			Latest: models.Version{VersionCode: 1, VersionName: "1.0"},
+		})
+		writeJSON(t, filepath.Join(dir, "versions", "1.json"), models.ControllerLayout{ID: id, VersionCode: 1})
+	}
+	if err := os.WriteFile(filepath.Join(root, "repo_json", "broken", "versions", "1.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := repository.NewManager(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestAnalyticsReportsUnreadableControllers(t *testing.T) {
+	a, err := NewAnalytics(newTestRepo(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Unreadable) != 1 || a.Unreadable[0].ID != "broken" || a.Unreadable[0].Error == "" {
+		t.Fatalf("unreadable = %+v", a.Unreadable)
+	}
+	for _, id := range a.NoScreenshots {
+		if id == "broken" {
+			t.Error("an unreadable controller is listed as having no screenshots")
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := a.WriteControllersCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := len(rows[0]) - 1
+	if rows[0][last] != "error" {
+		t.Fatalf("header = %v", rows[0])
+	}
+	for _, row := range rows[1:] {
+		if (row[0] == "broken") != (row[last] != "") {
+			t.Errorf("row %s has error %q", row[0], row[last])
+		}
+	}
+
+	html, err := a.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, "Unreadable controllers") || !strings.Contains(html, a.Unreadable[0].Error) {
+		t.Error("HTML report does not list the unreadable controller")
+	}
+}
+
+func TestAnalyticsLastChanged(t *testing.T) {
+	m := newTestRepo(t)
+	a, err := NewAnalytics(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range a.Controllers {
+		if !s.LastChanged.IsZero() {
+			t.Errorf("%s: last changed %v without an audit log or git history", s.ID, s.LastChanged)
+		}
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", m.RepoRoot}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com",
+			"GIT_COMMITTER_DATE=2024-03-01T12:00:00Z")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", "repo_json/good")
+	git("commit", "-q", "-m", "add good")
+
+	if a, err = NewAnalytics(m); err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, s := range a.Controllers {
+		switch s.ID {
+		case "good":
+			if !s.LastChanged.Equal(want) {
+				t.Errorf("good: last changed %v, want %v", s.LastChanged, want)
+			}
+		case "broken":
+			if !s.LastChanged.IsZero() {
+				t.Errorf("broken: last changed %v, want unknown", s.LastChanged)
+			}
+		}
+	}
+	if a.Unmaintained[0].ID != "good" {
+		t.Errorf("unmaintained = %v, want the dated controller first", a.Unmaintained)
+	}
+}
@@ -0,0 +1,30 @@
+package report
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/audit"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+// comparisonGap separates the two layouts of a comparison image.
+const comparisonGap = 16
+
+// RenderComparison draws the submitted layout (left) next to a published one
+// (right), each w x h, with the elements paired in match outlined so the
+// reviewer can see what was carried over.
+func RenderComparison(submitted, published *models.ControllerLayout, w, h int, match audit.Match) *image.NRGBA {
+	left := make(map[string]bool)
+	right := make(map[string]bool)
+	for _, p := range match.Pairs {
+		left[p.Submitted] = true
+		right[p.Published] = true
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, 2*w+comparisonGap, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, w, h), RenderLayout(submitted, w, h, left), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(w+comparisonGap, 0, 2*w+comparisonGap, h), RenderLayout(published, w, h, right), image.Point{}, draw.Src)
+	fillRect(img, image.Rect(w, 0, w+comparisonGap, h), hiddenColor)
+	return img
+}
@@ -0,0 +1,175 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/audit"
+)
+
+// Supported report languages.
+const (
+	LangZH = "zh"
+	LangEN = "en"
+)
+
+// Languages lists every language a report can be generated in.
+var Languages = []string{LangZH, LangEN}
+
+var messages = map[string]map[string]string{
+	LangZH: {
+		"title":          "控制器审核反馈",
+		"intro":          "感谢你提交控制器。很遗憾，本次提交未能通过审核，请根据以下说明修改后重新提交。",
+		"controller":     "控制器",
+		"version":        "版本",
+		"reviewer":       "审核人",
+		"date":           "日期",
+		"notes":          "审核意见",
+		"findings":       "发现的问题",
+		"no-findings":    "自动检查未发现问题，请参考审核意见。",
+		"layout":         "布局预览（红框标出有问题的元素）",
+		"element":        "元素",
+		"sev-error":      "错误",
+		"sev-warning":    "警告",
+		"sev-info":       "提示",
+		"closing":        "如有疑问，请直接回复本反馈。",
+		"unknown-rule":   "其他问题",
+		"suppressed":     "已豁免",
+		"justification":  "豁免理由",
+		"source-index":   "index.json",
+		"source-version": "version.json",
+		"source-layout":  "布局",
+		"list-sep":       "；",
+	},
+	LangEN: {
+		"title":          "Controller Review Feedback",
+		"intro":          "Thank you for submitting your controller. Unfortunately this submission was not accepted; please address the points below and submit again.",
+		"controller":     "Controller",
+		"version":        "Version",
+		"reviewer":       "Reviewer",
+		"date":           "Date",
+		"notes":          "Reviewer notes",
+		"findings":       "Problems found",
+		"no-findings":    "The automated checks found no problems; see the reviewer notes.",
+		"layout":         "Layout preview (problem elements outlined in red)",
+		"element":        "Element",
+		"sev-error":      "Error",
+		"sev-warning":    "Warning",
+		"sev-info":       "Note",
+		"closing":        "If anything is unclear, just reply to this feedback.",
+		"unknown-rule":   "Other problem",
+		"suppressed":     "Suppressed",
+		"justification":  "Justification",
+		"source-index":   "index.json",
+		"source-version": "version.json",
+		"source-layout":  "layout",
+		"list-sep":       "; ",
+	},
+}
+
+// ruleTitles gives each audit rule a short localized title for submitters.
+var ruleTitles = map[string]map[string]string{
+	LangZH: {
+		"package.missing-layout":         "缺少布局文件",
+		"package.missing-index":          "缺少或无法解析 index.json",
+		"package.invalid-id":             "控件 ID 不符合命名规则",
+		"package.id-mismatch":            "文件夹、index.json 与布局中的 ID 不一致",
+		"package.id-collision":           "控件 ID 与已有控件过于相似",
+		"package.similar-layout":         "布局与已发布控件高度相似",
+		"package.missing-version":        "缺少或无法解析 version.json",
+		"package.missing-icon":           "缺少图标 icon.png",
+		"package.icon-invalid":           "图标不是有效的 PNG 图片",
+		"package.icon-size":              "图标尺寸或文件大小不符合要求",
+		"package.icon-blank":             "图标为全透明或纯色",
+		"package.no-screenshots":         "没有提供截图",
+		"package.screenshot-invalid":     "截图无法解码",
+		"package.screenshot-orientation": "截图方向或尺寸不符合要求（需为横屏）",
+		"package.screenshot-duplicate":   "截图重复",
+		"index.missing-locale":           "缺少必需语言的名称或简介",
+		"index.duplicate-locale":         "同一语言重复填写",
+		"index.unknown-device":           "设备类型缺失或无效",
+		"index.device-mismatch":          "布局不适合所声明的设备",
+		"consistency.name":               "index.json 与布局中的名称不一致",
+		"consistency.author":             "version.json 与布局中的作者不一致",
+		"consistency.description":        "version.json 与布局中的描述不一致",
+		"consistency.version-name":       "version.json 与布局中的版本名不一致",
+		"consistency.version-code":       "version.json 与布局中的版本号不一致",
+		"layout.unknown-style":           "引用了不存在的样式",
+		"layout.duplicate-id":            "元素 ID 重复",
+		"layout.out-of-bounds":           "元素位置超出屏幕",
+		"layout.unknown-bind-group":      "绑定了不存在的按键组",
+		"layout.empty-group":             "空的按键组",
+		"layout.too-many-buttons":        "按键数量超过上限",
+		"layout.small-button":            "按键过小，难以点按",
+		"layout.disallowed-keycode":      "使用了不允许的按键码",
+		"layout.coverage":                "按键覆盖屏幕面积过大",
+		"layout.dense-region":            "屏幕局部按键过于密集",
+		"layout.toggle-depth":            "按键组切换层级过深",
+	},
+	LangEN: {
+		"package.missing-layout":         "Layout file missing",
+		"package.missing-index":          "index.json missing or invalid",
+		"package.invalid-id":             "Controller ID breaks the naming policy",
+		"package.id-mismatch":            "Folder, index.json and layout IDs disagree",
+		"package.id-collision":           "Controller ID is too similar to an existing one",
+		"package.similar-layout":         "Layout closely matches a published controller",
+		"package.missing-version":        "version.json missing or invalid",
+		"package.missing-icon":           "icon.png missing",
+		"package.icon-invalid":           "Icon is not a valid PNG image",
+		"package.icon-size":              "Icon dimensions or file size out of range",
+		"package.icon-blank":             "Icon is fully transparent or a single color",
+		"package.no-screenshots":         "No screenshots provided",
+		"package.screenshot-invalid":     "Screenshot cannot be decoded",
+		"package.screenshot-orientation": "Screenshot orientation or size out of range (must be landscape)",
+		"package.screenshot-duplicate":   "Duplicate screenshot",
+		"index.missing-locale":           "Name or introduction missing for a required language",
+		"index.duplicate-locale":         "Language listed more than once",
+		"index.unknown-device":           "Device types missing or invalid",
+		"index.device-mismatch":          "Layout does not suit a listed device",
+		"consistency.name":               "Name differs between index.json and the layout",
+		"consistency.author":             "Author differs between version.json and the layout",
+		"consistency.description":        "Description differs between version.json and the layout",
+		"consistency.version-name":       "Version name differs between version.json and the layout",
+		"consistency.version-code":       "Version code differs between version.json and the layout",
+		"layout.unknown-style":           "Reference to an undefined style",
+		"layout.duplicate-id":            "Duplicate element ID",
+		"layout.out-of-bounds":           "Element positioned off screen",
+		"layout.unknown-bind-group":      "Toggles an undefined view group",
+		"layout.empty-group":             "Empty view group",
+		"layout.too-many-buttons":        "Too many buttons",
+		"layout.small-button":            "Button too small to tap reliably",
+		"layout.disallowed-keycode":      "Keycode not allowed",
+		"layout.coverage":                "Controls cover too much of the screen",
+		"layout.dense-region":            "Too many buttons in one screen area",
+		"layout.toggle-depth":            "View groups nested too deeply",
+	},
+}
+
+func tr(lang, key string) string {
+	if m, ok := messages[lang]; ok {
+		if s, ok := m[key]; ok {
+			return s
+		}
+	}
+	return messages[LangEN][key]
+}
+
+// findingMessage words f for the submitter in lang. Consistency findings
+// list the disagreeing sources in lang; other findings keep their message.
+func findingMessage(lang string, f audit.Finding) string {
+	if len(f.Values) == 0 {
+		return f.Message
+	}
+	values := make([]string, len(f.Values))
+	for i, v := range f.Values {
+		values[i] = fmt.Sprintf("%s %q", tr(lang, "source-"+v.Source), v.Value)
+	}
+	return strings.Join(values, tr(lang, "list-sep"))
+}
+
+func ruleTitle(lang, ruleID string) string {
+	if t, ok := ruleTitles[lang][ruleID]; ok {
+		return t
+	}
+	return tr(lang, "unknown-rule")
+}
@@ -0,0 +1,296 @@
+package report
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/audit"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+// Rejection holds everything needed to explain a rejected package to its
+// author.
+type Rejection struct {
+	ControllerID string
+	Name         string
+	Names        map[string]string // localized names by locale
+	VersionName  string
+	VersionCode  int
+	Reviewer     string
+	Notes        string
+	Date         time.Time
+	Findings     []audit.Finding
+	Layout       *models.ControllerLayout
+
+	// PreviewWidth and PreviewHeight size the layout preview; zero uses
+	// models.PreviewWidth and models.PreviewHeight.
+	PreviewWidth, PreviewHeight int
+}
+
+// NewRejection builds a rejection from a parsed package and its findings.
+func NewRejection(pkg *utils.ParsedPackage, findings []audit.Finding, reviewer, notes string) *Rejection {
+	r := &Rejection{
+		ControllerID: pkg.ControllerID,
+		VersionCode:  pkg.VersionCode,
+		Reviewer:     reviewer,
+		Notes:        notes,
+		Date:         time.Now(),
+		Findings:     findings,
+		Layout:       pkg.Layout,
+	}
+	if pkg.IndexEntry != nil {
+		r.Name = pkg.IndexEntry.Name
+		r.Names = make(map[string]string)
+		for _, l := range pkg.IndexEntry.Locales() {
+			if l.Name != "" {
+				r.Names[l.Locale] = l.Name
+			}
+		}
+	}
+	if pkg.VersionInfo != nil {
+		r.VersionName = pkg.VersionInfo.Latest.VersionName
+	}
+	if pkg.Layout != nil {
+		if r.Name == "" {
+			r.Name = pkg.Layout.Name
+		}
+		if r.VersionName == "" {
+			r.VersionName = pkg.Layout.Version
+		}
+	}
+	return r
+}
+
+// Write renders the layout preview and writes a Markdown and an HTML
+// document per language into dir. It returns the paths it wrote.
+func (r *Rejection) Write(dir string, langs ...string) ([]string, error) {
+	if len(langs) == 0 {
+		langs = Languages
+	}
+	for _, lang := range langs {
+		if _, ok := messages[lang]; !ok {
+			return nil, fmt.Errorf("unsupported report language %q", lang)
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	var written []string
+	var pngData []byte
+	imageName := ""
+	if r.Layout != nil {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, r.Preview()); err != nil {
+			return nil, err
+		}
+		pngData = buf.Bytes()
+		imageName = r.ControllerID + "-layout.png"
+		imagePath := filepath.Join(dir, imageName)
+		if err := os.WriteFile(imagePath, pngData, 0644); err != nil {
+			return nil, err
+		}
+		written = append(written, imagePath)
+	}
+
+	for _, lang := range langs {
+		base := filepath.Join(dir, fmt.Sprintf("%s-rejection.%s", r.ControllerID, lang))
+		if err := os.WriteFile(base+".md", []byte(r.Markdown(lang, imageName)), 0644); err != nil {
+			return written, err
+		}
+		written = append(written, base+".md")
+
+		html, err := r.HTML(lang, pngData)
+		if err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(base+".html", []byte(html), 0644); err != nil {
+			return written, err
+		}
+		written = append(written, base+".html")
+	}
+	return written, nil
+}
+
+// Preview renders the layout with every element that has an unsuppressed
+// finding highlighted.
+func (r *Rejection) Preview() *image.NRGBA {
+	highlight := make(map[string]bool)
+	for _, f := range r.Findings {
+		if f.ElementID != "" && !f.Suppressed {
+			highlight[f.ElementID] = true
+		}
+	}
+	w, h := models.PreviewWidth, models.PreviewHeight
+	if r.PreviewWidth > 0 && r.PreviewHeight > 0 {
+		w, h = r.PreviewWidth, r.PreviewHeight
+	}
+	return RenderLayout(r.Layout, w, h, highlight)
+}
+
+// Markdown returns the feedback document in lang. imageName, if set, is
+// linked as the layout preview.
+func (r *Rejection) Markdown(lang, imageName string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s: %s\n\n", tr(lang, "title"), r.displayName(lang))
+	fmt.Fprintf(&b, "%s\n\n", tr(lang, "intro"))
+	fmt.Fprintf(&b, "- **%s**: `%s`\n", tr(lang, "controller"), r.ControllerID)
+	fmt.Fprintf(&b, "- **%s**: %s (%d)\n", tr(lang, "version"), r.VersionName, r.VersionCode)
+	if r.Reviewer != "" {
+		fmt.Fprintf(&b, "- **%s**: %s\n", tr(lang, "reviewer"), r.Reviewer)
+	}
+	fmt.Fprintf(&b, "- **%s**: %s\n\n", tr(lang, "date"), r.Date.Format("2006-01-02"))
+
+	if strings.TrimSpace(r.Notes) != "" {
+		fmt.Fprintf(&b, "## %s\n\n%s\n\n", tr(lang, "notes"), strings.TrimSpace(r.Notes))
+	}
+
+	fmt.Fprintf(&b, "## %s\n\n", tr(lang, "findings"))
+	if len(r.Findings) == 0 {
+		fmt.Fprintf(&b, "%s\n\n", tr(lang, "no-findings"))
+	}
+	for _, f := range r.Findings {
+		fmt.Fprintf(&b, "- **%s** — %s", severityLabel(lang, f), ruleTitle(lang, f.RuleID))
+		if f.ElementID != "" {
+			fmt.Fprintf(&b, " (%s `%s`)", tr(lang, "element"), f.ElementID)
+		}
+		fmt.Fprintf(&b, "\n  `%s`: %s\n", f.RuleID, findingMessage(lang, f))
+		if f.Suppressed {
+			fmt.Fprintf(&b, "  %s: %s\n", tr(lang, "justification"), f.Justification)
+		}
+	}
+	if len(r.Findings) > 0 {
+		b.WriteString("\n")
+	}
+
+	if imageName != "" {
+		fmt.Fprintf(&b, "## %s\n\n![layout](%s)\n\n", tr(lang, "layout"), imageName)
+	}
+	fmt.Fprintf(&b, "%s\n", tr(lang, "closing"))
+	return b.String()
+}
+
+// HTML returns a self-contained feedback page in lang with the layout
+// preview embedded as a data URI.
+func (r *Rejection) HTML(lang string, pngData []byte) (string, error) {
+	type finding struct {
+		Severity string
+		Class    string
+		Title    string
+		RuleID   string
+		Element  string
+		Message  string
+		Reason   string
+	}
+	data := struct {
+		Lang     string
+		T        map[string]string
+		R        *Rejection
+		Name     string
+		Date     string
+		Findings []finding
+		Image    template.URL
+	}{
+		Lang: lang,
+		T:    messages[lang],
+		R:    r,
+		Name: r.displayName(lang),
+		Date: r.Date.Format("2006-01-02"),
+	}
+	if data.T == nil {
+		data.T = messages[LangEN]
+	}
+	for _, f := range r.Findings {
+		class := string(f.Severity)
+		if f.Suppressed {
+			class = "suppressed"
+		}
+		data.Findings = append(data.Findings, finding{
+			Severity: severityLabel(lang, f),
+			Class:    class,
+			Title:    ruleTitle(lang, f.RuleID),
+			RuleID:   f.RuleID,
+			Element:  f.ElementID,
+			Message:  findingMessage(lang, f),
+			Reason:   f.Justification,
+		})
+	}
+	if len(pngData) > 0 {
+		data.Image = template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(pngData))
+	}
+
+	var buf bytes.Buffer
+	if err := rejectionTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// severityLabel names f's severity in lang, marking accepted findings.
+func severityLabel(lang string, f audit.Finding) string {
+	label := tr(lang, "sev-"+string(f.Severity))
+	if f.Suppressed {
+		label = tr(lang, "suppressed") + " · " + label
+	}
+	return label
+}
+
+func (r *Rejection) displayName(lang string) string {
+	if name, ok := r.Names[lang]; ok {
+		return name
+	}
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.ControllerID
+}
+
+var rejectionTemplate = template.Must(template.New("rejection").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{.T.title}}: {{.Name}}</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 2em auto; color: #222; }
+dl { display: grid; grid-template-columns: max-content auto; gap: 4px 16px; }
+dt { font-weight: bold; }
+li { margin-bottom: 8px; }
+.error { color: #c0392b; }
+.warning { color: #b7950b; }
+.info { color: #566573; }
+.suppressed { color: #7f8c8d; }
+code { background: #f2f3f4; padding: 0 4px; }
+img { max-width: 100%; border: 1px solid #ccc; }
+.notes { white-space: pre-wrap; background: #f8f9f9; padding: 12px; }
+</style>
+</head>
+<body>
+<h1>{{.T.title}}: {{.Name}}</h1>
+<p>{{.T.intro}}</p>
+<dl>
+<dt>{{.T.controller}}</dt><dd><code>{{.R.ControllerID}}</code></dd>
+<dt>{{.T.version}}</dt><dd>{{.R.VersionName}} ({{.R.VersionCode}})</dd>
+{{if .R.Reviewer}}<dt>{{.T.reviewer}}</dt><dd>{{.R.Reviewer}}</dd>{{end}}
+<dt>{{.T.date}}</dt><dd>{{.Date}}</dd>
+</dl>
+{{if .R.Notes}}<h2>{{.T.notes}}</h2>
+<div class="notes">{{.R.Notes}}</div>{{end}}
+<h2>{{.T.findings}}</h2>
+{{if .Findings}}<ul>
+{{range .Findings}}<li><strong class="{{.Class}}">{{.Severity}}</strong> — {{.Title}}{{if .Element}} ({{$.T.element}} <code>{{.Element}}</code>){{end}}<br><code>{{.RuleID}}</code>: {{.Message}}{{if .Reason}}<br>{{$.T.justification}}: {{.Reason}}{{end}}</li>
+{{end}}</ul>{{else}}<p>{{index .T "no-findings"}}</p>{{end}}
+{{if .Image}}<h2>{{.T.layout}}</h2>
+<img src="{{.Image}}" alt="layout">{{end}}
+<p>{{.T.closing}}</p>
+</body>
+</html>
+`))
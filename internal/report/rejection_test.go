@@ -0,0 +1,192 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/audit"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+func TestConsistencyFindingIsLocalized(t *testing.T) {
+	f := audit.Finding{
+		RuleID:   "consistency.name",
+		Severity: audit.SeverityWarning,
+		Message:  `name differs: index "Demo", layout "Other"`,
+		Values:   []utils.SourceValue{{Source: utils.SourceIndex, Value: "Demo"}, {Source: utils.SourceLayout, Value: "Other"}},
+	}
+	r := &Rejection{ControllerID: "demo", Findings: []audit.Finding{f}}
+	tests := []struct{ lang, want string }{
+		{LangZH, `index.json "Demo"；布局 "Other"`},
+		{LangEN, `index.json "Demo"; layout "Other"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			md := r.Markdown(tt.lang, "")
+			if !strings.Contains(md, tt.want) {
+				t.Errorf("Markdown is missing %q:\n%s", tt.want, md)
+			}
+			if strings.Contains(md, "differs:") {
+				t.Errorf("Markdown kept the English message:\n%s", md)
+			}
+			html, err := r.HTML(tt.lang, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(html, "Other") || strings.Contains(html, "differs:") {
+				t.Errorf("HTML does not localize the finding:\n%s", html)
+			}
+		})
+	}
+}
+
+func goldenRejection() *Rejection {
+	return &Rejection{
+		ControllerID: "demo",
+		Name:         "Demo",
+		Names:        map[string]string{"zh": "演示"},
+		VersionName:  "1.2",
+		VersionCode:  3,
+		Reviewer:     "alice",
+		Notes:        "Please enlarge the fire button.",
+		Date:         time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		Findings: []audit.Finding{
+			{RuleID: "layout.small-button", Severity: audit.SeverityError, ElementID: "fire", Message: "button is 20dp wide"},
+			{RuleID: "layout.coverage", Severity: audit.SeverityWarning, Message: "controls cover 80% of the screen", Suppressed: true, Justification: "racing layout"},
+		},
+	}
+}
+
+func TestRejectionMarkdown(t *testing.T) {
+	tests := map[string]string{
+		LangZH: `# 控制器审核反馈: 演示
+
+感谢你提交控制器。很遗憾，本次提交未能通过审核，请根据以下说明修改后重新提交。
+
+- **控制器**: ` + "`demo`" + `
+- **版本**: 1.2 (3)
+- **审核人**: alice
+- **日期**: 2026-01-02
+
+## 审核意见
+
+Please enlarge the fire button.
+
+## 发现的问题
+
+- **错误** — 按键过小，难以点按 (元素 ` + "`fire`" + `)
+  ` + "`layout.small-button`" + `: button is 20dp wide
+- **已豁免 · 警告** — 按键覆盖屏幕面积过大
+  ` + "`layout.coverage`" + `: controls cover 80% of the screen
+  豁免理由: racing layout
+
+## 布局预览（红框标出有问题的元素）
+
+![layout](demo-layout.png)
+
+如有疑问，请直接回复本反馈。
+`,
+		LangEN: `# Controller Review Feedback: Demo
+
+Thank you for submitting your controller. Unfortunately this submission was not accepted; please address the points below and submit again.
+
+- **Controller**: ` + "`demo`" + `
+- **Version**: 1.2 (3)
+- **Reviewer**: alice
+- **Date**: 2026-01-02
+
+## Reviewer notes
+
+Please enlarge the fire button.
+
+## Problems found
+
+- **Error** — Button too small to tap reliably (Element ` + "`fire`" + `)
+  ` + "`layout.small-button`" + `: button is 20dp wide
+- **Suppressed · Warning** — Controls cover too much of the screen
+  ` + "`layout.coverage`" + `: controls cover 80% of the screen
+  Justification: racing layout
+
+## Layout preview (problem elements outlined in red)
+
+![layout](demo-layout.png)
+
+If anything is unclear, just reply to this feedback.
+`,
+	}
+	for lang, want := range tests {
+		t.Run(lang, func(t *testing.T) {
+			if got := goldenRejection().Markdown(lang, "demo-layout.png"); got != want {
+				t.Errorf("Markdown(%s) =\n%s\nwant\n%s", lang, got, want)
+			}
+		})
+	}
+}
+
+func TestRejectionHTML(t *testing.T) {
+	tests := map[string][]string{
+		LangZH: {
+			`<html lang="zh">`,
+			`<title>控制器审核反馈: 演示</title>`,
+			`<strong class="error">错误</strong> — 按键过小，难以点按 (元素 <code>fire</code>)`,
+			`<strong class="suppressed">已豁免 · 警告</strong>`,
+			`豁免理由: racing layout`,
+		},
+		LangEN: {
+			`<html lang="en">`,
+			`<title>Controller Review Feedback: Demo</title>`,
+			`<strong class="error">Error</strong> — Button too small to tap reliably (Element <code>fire</code>)`,
+			`<strong class="suppressed">Suppressed · Warning</strong>`,
+			`Justification: racing layout`,
+		},
+	}
+	for lang, want := range tests {
+		t.Run(lang, func(t *testing.T) {
+			html, err := goldenRejection().HTML(lang, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range want {
+				if !strings.Contains(html, s) {
+					t.Errorf("HTML is missing %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestEveryRuleHasATitle(t *testing.T) {
+	for _, lang := range Languages {
+		for _, rule := range audit.Rules {
+			if _, ok := ruleTitles[lang][rule.ID]; !ok {
+				t.Errorf("%s has no %s title", rule.ID, lang)
+			}
+		}
+		for key := range messages[LangEN] {
+			if _, ok := messages[lang][key]; !ok {
+				t.Errorf("message %q has no %s translation", key, lang)
+			}
+		}
+	}
+}
+
+func TestWriteRejectsUnknownLanguage(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	if _, err := goldenRejection().Write(dir, LangEN, "fr"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("files were written for an unknown language: %v", err)
+	}
+
+	files, err := goldenRejection().Write(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2*len(Languages) {
+		t.Errorf("wrote %v, want a Markdown and HTML file per language", files)
+	}
+}
@@ -0,0 +1,168 @@
+package report
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	backgroundColor = color.NRGBA{R: 17, G: 26, B: 36, A: 255}
+	highlightColor  = color.NRGBA{R: 231, G: 76, B: 60, A: 255}
+	hiddenColor     = color.NRGBA{R: 136, G: 153, B: 170, A: 96}
+)
+
+// RenderLayout draws the visible elements of a layout onto a w x h image.
+// Elements whose IDs are in highlight are outlined in red; highlighted
+// elements in hidden view groups are drawn as translucent ghosts so the
+// reader can still locate them.
+func RenderLayout(layout *models.ControllerLayout, w, h int, highlight map[string]bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+	if layout == nil {
+		return img
+	}
+
+	buttonStyles := make(map[string]models.ButtonStyle)
+	for _, s := range layout.ButtonStyles {
+		buttonStyles[s.Name] = s
+	}
+	directionStyles := make(map[string]models.DirectionStyle)
+	for _, s := range layout.DirectionStyles {
+		directionStyles[s.Name] = s
+	}
+
+	var marked []image.Rectangle
+	var labels []string
+	for _, group := range layout.ViewGroups {
+		visible := group.Visibility == models.VisibilityShown
+		if highlight[group.ID] {
+			visible = true
+		}
+
+		for _, btn := range group.ViewData.ButtonList {
+			r := elementRect(btn.BaseInfo, w, h)
+			if visible {
+				style := buttonStyles[btn.Style]
+				fillRect(img, r, argb(style.FillColor))
+				strokeRect(img, r, argb(style.StrokeColor), 1)
+				drawLabel(img, r, btn.Text, argb(style.TextColor))
+			} else if highlight[btn.ID] {
+				fillRect(img, r, hiddenColor)
+			}
+			if highlight[btn.ID] {
+				marked = append(marked, r)
+				labels = append(labels, btn.ID)
+			}
+		}
+		for _, dir := range group.ViewData.DirectionList {
+			r := elementRect(dir.BaseInfo, w, h)
+			if visible {
+				fill, stroke := directionColors(directionStyles[dir.Style])
+				fillRect(img, r, fill)
+				strokeRect(img, r, stroke, 1)
+			} else if highlight[dir.ID] {
+				fillRect(img, r, hiddenColor)
+			}
+			if highlight[dir.ID] {
+				marked = append(marked, r)
+				labels = append(labels, dir.ID)
+			}
+		}
+	}
+
+	// Highlights go on top so overlapping elements cannot hide them.
+	// Off-screen elements are pinned to the nearest edge.
+	for i, r := range marked {
+		r = pinInside(r, img.Bounds())
+		strokeRect(img, r.Inset(-2), highlightColor, 3)
+		drawText(img, r.Min.X, r.Min.Y-6, labels[i], highlightColor)
+	}
+	return img
+}
+
+func elementRect(info models.BaseInfo, w, h int) image.Rectangle {
+	x, y, ew, eh := info.Rect(float64(w), float64(h))
+	return image.Rect(int(x), int(y), int(x+ew), int(y+eh))
+}
+
+func pinInside(r, bounds image.Rectangle) image.Rectangle {
+	if r.Dx() > bounds.Dx() || r.Dy() > bounds.Dy() {
+		return r.Intersect(bounds)
+	}
+	var d image.Point
+	if r.Min.X < bounds.Min.X {
+		d.X = bounds.Min.X - r.Min.X
+	} else if r.Max.X > bounds.Max.X {
+		d.X = bounds.Max.X - r.Max.X
+	}
+	if r.Min.Y < bounds.Min.Y {
+		d.Y = bounds.Min.Y - r.Min.Y
+	} else if r.Max.Y > bounds.Max.Y {
+		d.Y = bounds.Max.Y - r.Max.Y
+	}
+	return r.Add(d)
+}
+
+func fillRect(img *image.NRGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r.Intersect(img.Bounds()), image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+func strokeRect(img *image.NRGBA, r image.Rectangle, c color.Color, width int) {
+	fillRect(img, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+width), c)
+	fillRect(img, image.Rect(r.Min.X, r.Max.Y-width, r.Max.X, r.Max.Y), c)
+	fillRect(img, image.Rect(r.Min.X, r.Min.Y, r.Min.X+width, r.Max.Y), c)
+	fillRect(img, image.Rect(r.Max.X-width, r.Min.Y, r.Max.X, r.Max.Y), c)
+}
+
+func drawLabel(img *image.NRGBA, r image.Rectangle, text string, c color.Color) {
+	if text == "" {
+		return
+	}
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, text).Ceil()
+	x := r.Min.X + (r.Dx()-width)/2
+	y := r.Min.Y + (r.Dy()+face.Ascent-face.Descent)/2
+	drawText(img, x, y, text, c)
+}
+
+func drawText(img *image.NRGBA, x, y int, text string, c color.Color) {
+	if maxX := img.Bounds().Dx() - font.MeasureString(basicfont.Face7x13, text).Ceil(); x > maxX {
+		x = maxX
+	}
+	if x < 0 {
+		x = 0
+	}
+	if y < basicfont.Face7x13.Ascent {
+		y = basicfont.Face7x13.Ascent
+	}
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(text)
+}
+
+func directionColors(style models.DirectionStyle) (fill, stroke color.NRGBA) {
+	if style.StyleType == "ROCKER" {
+		return argb(style.RockerStyle.BgFillColor), argb(style.RockerStyle.BgStrokeColor)
+	}
+	return argb(style.ButtonStyle.FillColor), argb(style.ButtonStyle.StrokeColor)
+}
+
+// argb converts FCL's packed ARGB int32 colors.
+func argb(val int) color.NRGBA {
+	return color.NRGBA{
+		A: uint8((val >> 24) & 0xff),
+		R: uint8((val >> 16) & 0xff),
+		G: uint8((val >> 8) & 0xff),
+		B: uint8(val & 0xff),
+	}
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/imaging"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+// testPackage returns a new controller submission with one screenshot.
+func testPackage(t *testing.T, id string) *utils.ParsedPackage {
+	t.Helper()
+	dir := t.TempDir()
+	shot := filepath.Join(dir, "1.png")
+	if err := imaging.WritePNG(shot, image.NewNRGBA(image.Rect(0, 0, 16, 9))); err != nil {
+		t.Fatal(err)
+	}
+	return &utils.ParsedPackage{
+		ControllerID: id,
+		VersionCode:  1,
+		Layout:       testLayout(id, 1),
+		VersionInfo:  &models.RepoVersion{Author: "alice", Latest: models.Version{VersionCode: 1, VersionName: "1.1"}},
+		IndexEntry:   &models.IndexEntry{ID: id, Lang: "en", Name: id, Device: []models.Device{models.DevicePhone}},
+		Screenshots:  []string{shot},
+		TempDir:      dir,
+	}
+}
+
+func TestApplyUpdate(t *testing.T) {
+	m := newTestRepo(t)
+	if err := m.ApplyUpdate(testPackage(t, "demo"), testReview); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.IDs(); len(got) != 1 || got[0] != "demo" {
+		t.Errorf("IDs() = %v, want [demo]", got)
+	}
+	for _, name := range []string{"version.json", "versions/1.json", "screenshots/1.png"} {
+		if _, err := os.Stat(filepath.Join(m.RepoRoot, "repo_json", "demo", name)); err != nil {
+			t.Error(err)
+		}
+	}
+	if rec := lastAudit(t, m); rec.Action != ActionApply || rec.NewVersionCode != 1 {
+		t.Errorf("audit record = %+v", rec)
+	}
+}
+
+func TestApplyUpdateRejectsBrokenImages(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(pkg *utils.ParsedPackage) string
+	}{
+		{"icon", func(pkg *utils.ParsedPackage) string {
+			pkg.IconPath = filepath.Join(pkg.TempDir, "icon.png")
+			return pkg.IconPath
+		}},
+		{"last screenshot", func(pkg *utils.ParsedPackage) string {
+			pkg.Screenshots = append(pkg.Screenshots, filepath.Join(pkg.TempDir, "2.png"))
+			return pkg.Screenshots[1]
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestRepo(t)
+			pkg := testPackage(t, "demo")
+			if err := os.WriteFile(tt.corrupt(pkg), []byte("not an image"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := m.ApplyUpdate(pkg, testReview); err == nil {
+				t.Fatal("expected an error")
+			}
+			if _, err := os.Stat(filepath.Join(m.RepoRoot, "repo_json", "demo")); !os.IsNotExist(err) {
+				t.Errorf("repo_json/demo was written: %v", err)
+			}
+			if len(m.Index) != 0 {
+				t.Errorf("index = %v, want empty", m.IDs())
+			}
+		})
+	}
+}
+
+func TestApplyUpdateControllerIDPolicy(t *testing.T) {
+	m := newTestRepo(t)
+	addController(t, m, "ab", 1) // shorter than the policy allows
+
+	legacy := testPackage(t, "ab")
+	legacy.IsUpdate = true
+	legacy.VersionCode = 2
+	legacy.Layout = testLayout("ab", 2)
+	legacy.VersionInfo.Latest.VersionCode = 2
+	if err := m.ApplyUpdate(legacy, testReview); err != nil {
+		t.Errorf("updating a legacy ID: %v", err)
+	}
+
+	if err := m.ApplyUpdate(testPackage(t, "xy"), testReview); err == nil {
+		t.Error("a new controller with an invalid ID was applied")
+	}
+}
+
+func TestApplyUpdateRejectsMismatchedIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		mismatch func(pkg *utils.ParsedPackage)
+	}{
+		{"index.json", func(pkg *utils.ParsedPackage) { pkg.IndexEntry.ID = "popular" }},
+		{"layout", func(pkg *utils.ParsedPackage) { pkg.Layout.ID = "popular" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestRepo(t)
+			addController(t, m, "popular", 1)
+			m.Index[0].Categories = []int{1, 2}
+			if err := m.Save(); err != nil {
+				t.Fatal(err)
+			}
+
+			pkg := testPackage(t, "attacker")
+			pkg.IndexEntry.Name = "Hijacked"
+			tt.mismatch(pkg)
+			if err := m.ApplyUpdate(pkg, testReview); err == nil {
+				t.Fatal("a package with mismatched IDs was applied")
+			}
+			if got := m.IDs(); len(got) != 1 || got[0] != "popular" {
+				t.Errorf("IDs() = %v, want [popular]", got)
+			}
+			if e := m.Index[0]; e.Name != "popular" || len(e.Categories) != 2 {
+				t.Errorf("popular entry changed: %+v", e)
+			}
+			if _, err := os.Stat(filepath.Join(m.RepoRoot, "repo_json", "attacker")); !os.IsNotExist(err) {
+				t.Errorf("repo_json/attacker was written: %v", err)
+			}
+		})
+	}
+}
+
+// writeJPEGWithExif writes a JPEG carrying an APP1 Exif segment that
+// contains marker.
+func writeJPEGWithExif(t *testing.T, path, marker string) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 800, 450)), nil); err != nil {
+		t.Fatal(err)
+	}
+	payload := append([]byte("Exif\x00\x00"), marker...)
+	segment := []byte{0xFF, 0xE1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}
+	data := append([]byte{0xFF, 0xD8}, append(append(segment, payload...), buf.Bytes()[2:]...)...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyUpdateRewritesScreenshots(t *testing.T) {
+	m := newTestRepo(t)
+	m.DownscaleScreenshots = true
+	pkg := testPackage(t, "demo")
+	big := filepath.Join(pkg.TempDir, "zz-big.png")
+	if err := imaging.WritePNG(big, image.NewNRGBA(image.Rect(0, 0, 2400, 1350))); err != nil {
+		t.Fatal(err)
+	}
+	photo := filepath.Join(pkg.TempDir, "aa-photo.jpg")
+	writeJPEGWithExif(t, photo, "GPS-SECRET")
+	pkg.Screenshots = []string{big, photo, pkg.Screenshots[0]}
+	if err := m.ApplyUpdate(pkg, testReview); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(m.RepoRoot, "repo_json", "demo", "screenshots")
+	if got := utils.ListScreenshots(dir); len(got) != 3 {
+		t.Fatalf("screenshots = %v, want 3", got)
+	}
+	// Screenshots keep the package order under canonical names.
+	for i, want := range []struct{ w, h int }{{1920, 1080}, {800, 450}, {16, 9}} {
+		path := filepath.Join(dir, fmt.Sprintf("%d.png", i+1))
+		info, _, err := imaging.Inspect(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Format != "png" || info.Width != want.w || info.Height != want.h {
+			t.Errorf("%d.png = %+v, want a %dx%d PNG", i+1, info, want.w, want.h)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "2.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("GPS-SECRET")) || bytes.Contains(data, []byte("Exif")) {
+		t.Error("EXIF metadata survived the re-encode")
+	}
+	version, err := m.readVersion("demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version.Screenshot != 3 {
+		t.Errorf("version.json screenshot count = %d, want 3", version.Screenshot)
+	}
+
+	// An update with fewer screenshots leaves none of the old ones behind.
+	update := testPackage(t, "demo")
+	update.IsUpdate = true
+	update.VersionCode = 2
+	update.Layout = testLayout("demo", 2)
+	update.VersionInfo.Latest.VersionCode = 2
+	if err := m.ApplyUpdate(update, testReview); err != nil {
+		t.Fatal(err)
+	}
+	if got := utils.ListScreenshots(dir); len(got) != 1 || filepath.Base(got[0]) != "1.png" {
+		t.Errorf("screenshots after update = %v, want [1.png]", got)
+	}
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+// SidecarDir holds auditor-only state next to the repository data.
+const SidecarDir = ".auditor"
+
+const auditLogFile = "audit-log.jsonl"
+
+const (
+	ActionApply  = "apply"
+	ActionReject = "reject"
+)
+
+// Review carries the reviewer's decision that is recorded with an update.
+type Review struct {
+	Reviewer string
+	Notes    string
+	Findings models.FindingsSummary
+}
+
+// AuditLogPath returns the location of the JSON Lines audit log.
+func (m *Manager) AuditLogPath() string {
+	return filepath.Join(m.RepoRoot, SidecarDir, auditLogFile)
+}
+
+// AppendAudit appends a record to the audit log. Complete records are never
+// rewritten; a partial one left by an interrupted write is dropped first.
+func (m *Manager) AppendAudit(rec models.AuditRecord) error {
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = time.Now().UTC()
+	}
+	if rec.Findings.Rules == nil {
+		rec.Findings.Rules = []string{}
+	}
+
+	logPath := m.AuditLogPath()
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(logPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	end, err := completeLength(f)
+	if err != nil {
+		return err
+	}
+	if err := f.Truncate(end); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(append(data, '\n'), end)
+	return err
+}
+
+// completeLength returns the length of f up to and including its last
+// newline, i.e. without a trailing partial record.
+func completeLength(f *os.File) (int64, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	buf := make([]byte, 4096)
+	for end := st.Size(); end > 0; {
+		start := max(0, end-int64(len(buf)))
+		n, err := f.ReadAt(buf[:end-start], start)
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			return start + int64(i) + 1, nil
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// RecordRejection logs that a package was rejected without touching the
+// repository data. With git enabled the record is left uncommitted and goes
+// in with the next committed change.
+func (m *Manager) RecordRejection(pkg *utils.ParsedPackage, review Review) error {
+	if strings.TrimSpace(review.Reviewer) == "" {
+		return fmt.Errorf("reviewer name is required")
+	}
+	return m.AppendAudit(models.AuditRecord{
+		Action:         ActionReject,
+		ControllerID:   pkg.ControllerID,
+		OldVersionCode: m.latestVersionCode(pkg.ControllerID),
+		NewVersionCode: pkg.VersionCode,
+		Reviewer:       review.Reviewer,
+		PackageSHA256:  pkg.SHA256,
+		Findings:       review.Findings,
+		Notes:          review.Notes,
+	})
+}
+
+// AuditLog returns the recorded decisions for a controller, oldest first.
+// An empty id returns every record. A partial final record, left by an
+// interrupted write, is skipped.
+func (m *Manager) AuditLog(id string) ([]models.AuditRecord, error) {
+	f, err := os.Open(m.AuditLogPath())
+	if os.IsNotExist(err) {
+		return []models.AuditRecord{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records := []models.AuditRecord{}
+	r := bufio.NewReader(f)
+	for line := 1; ; line++ {
+		data, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		partial := err == io.EOF
+		if len(bytes.TrimSpace(data)) > 0 {
+			var rec models.AuditRecord
+			if jerr := json.Unmarshal(data, &rec); jerr != nil {
+				if partial {
+					break
+				}
+				return nil, fmt.Errorf("audit log line %d: %v", line, jerr)
+			}
+			if id == "" || rec.ControllerID == id {
+				records = append(records, rec)
+			}
+		}
+		if partial {
+			break
+		}
+	}
+	return records, nil
+}
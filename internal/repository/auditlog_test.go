@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+// auditKeys returns "id@code" for each record.
+func auditKeys(records []models.AuditRecord) []string {
+	keys := []string{}
+	for _, r := range records {
+		keys = append(keys, fmt.Sprintf("%s@%d", r.ControllerID, r.NewVersionCode))
+	}
+	return keys
+}
+
+func appendRecords(t *testing.T, m *Manager, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		var rec models.AuditRecord
+		if _, err := fmt.Sscanf(key, "%1s@%d", &rec.ControllerID, &rec.NewVersionCode); err != nil {
+			t.Fatal(err)
+		}
+		rec.Action = ActionApply
+		if err := m.AppendAudit(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	m := newTestRepo(t)
+	if got, err := m.AuditLog(""); err != nil || len(got) != 0 {
+		t.Fatalf("AuditLog() on a new repository = %v, %v", got, err)
+	}
+	appendRecords(t, m, "a@1", "b@1", "a@2")
+
+	tests := []struct {
+		id   string
+		want []string
+	}{
+		{"", []string{"a@1", "b@1", "a@2"}},
+		{"a", []string{"a@1", "a@2"}},
+		{"b", []string{"b@1"}},
+		{"c", []string{}},
+	}
+	for _, tt := range tests {
+		records, err := m.AuditLog(tt.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := auditKeys(records); !slices.Equal(got, tt.want) {
+			t.Errorf("AuditLog(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+		for _, rec := range records {
+			if rec.Timestamp.IsZero() || rec.Findings.Rules == nil {
+				t.Errorf("record was not completed: %+v", rec)
+			}
+		}
+	}
+}
+
+func TestAuditLogTruncatedRecord(t *testing.T) {
+	m := newTestRepo(t)
+	appendRecords(t, m, "a@1", "b@1")
+	f, err := os.OpenFile(m.AuditLogPath(), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.WriteString(`{"action":"apply","controllerId":"c","newVer`)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := m.AuditLog("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := auditKeys(records), []string{"a@1", "b@1"}; !slices.Equal(got, want) {
+		t.Errorf("AuditLog() = %v, want %v", got, want)
+	}
+
+	// The next record replaces the partial one instead of joining it.
+	appendRecords(t, m, "a@2")
+	records, err = m.AuditLog("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := auditKeys(records), []string{"a@1", "b@1", "a@2"}; !slices.Equal(got, want) {
+		t.Errorf("AuditLog() after appending = %v, want %v", got, want)
+	}
+}
+
+func TestAuditLogCorruptRecord(t *testing.T) {
+	m := newTestRepo(t)
+	appendRecords(t, m, "a@1")
+	f, err := os.OpenFile(m.AuditLogPath(), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.WriteString("not json\n")
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendRecords(t, m, "b@1")
+	if _, err := m.AuditLog(""); err == nil {
+		t.Error("a corrupt record in the middle of the log was ignored")
+	}
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+const (
+	ActionCategoryAdd    = "category-add"
+	ActionCategoryRename = "category-rename"
+	ActionCategoryDelete = "category-delete"
+)
+
+// CreateCategory adds a category to category.json. A nil id picks the next
+// free ID.
+func (m *Manager) CreateCategory(id *int, texts []models.LocalizedText, review Review) (models.Category, error) {
+	if strings.TrimSpace(review.Reviewer) == "" {
+		return models.Category{}, fmt.Errorf("reviewer name is required")
+	}
+	var newID int
+	if id != nil {
+		newID = *id
+	} else {
+		for _, c := range m.Categories {
+			newID = max(newID, c.ID+1)
+		}
+	}
+	if newID < 0 {
+		return models.Category{}, fmt.Errorf("category ID must not be negative")
+	}
+	if m.categoryIndex(newID) >= 0 {
+		return models.Category{}, fmt.Errorf("category %d already exists", newID)
+	}
+	texts, err := normalizeTexts(texts)
+	if err != nil {
+		return models.Category{}, err
+	}
+
+	cat := models.Category{ID: newID, Lang: texts}
+	categories := append(slices.Clone(m.Categories), cat)
+	subject := fmt.Sprintf("Add category %d (%s)", newID, cat.Text("en"))
+	err = m.commitCategoryChange(fmt.Sprintf("auditor/category-%d-add", newID), ActionCategoryAdd, newID, subject, review, m.Index, categories)
+	return cat, err
+}
+
+// RenameCategory updates the names of a category. Locales not mentioned in
+// texts keep their current name.
+func (m *Manager) RenameCategory(id int, texts []models.LocalizedText, review Review) error {
+	if strings.TrimSpace(review.Reviewer) == "" {
+		return fmt.Errorf("reviewer name is required")
+	}
+	idx := m.categoryIndex(id)
+	if idx < 0 {
+		return fmt.Errorf("category %d does not exist", id)
+	}
+	texts, err := normalizeTexts(texts)
+	if err != nil {
+		return err
+	}
+
+	merged := append([]models.LocalizedText(nil), m.Categories[idx].Lang...)
+	for _, t := range texts {
+		found := false
+		for i := range merged {
+			if merged[i].Locale == t.Locale {
+				merged[i].Text = t.Text
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, t)
+		}
+	}
+
+	categories := slices.Clone(m.Categories)
+	categories[idx].Lang = merged
+	subject := fmt.Sprintf("Rename category %d", id)
+	return m.commitCategoryChange(fmt.Sprintf("auditor/category-%d-rename", id), ActionCategoryRename, id, subject, review, m.Index, categories)
+}
+
+// MergeCategories moves every controller in category from into category
+// into and deletes from.
+func (m *Manager) MergeCategories(from, into int, review Review) error {
+	if from == into {
+		return fmt.Errorf("cannot merge category %d into itself", from)
+	}
+	return m.DeleteCategory(from, &into, review)
+}
+
+// DeleteCategory removes a category. Controllers that referenced it are
+// moved to reassignTo, or simply lose the category if reassignTo is nil.
+func (m *Manager) DeleteCategory(id int, reassignTo *int, review Review) error {
+	if strings.TrimSpace(review.Reviewer) == "" {
+		return fmt.Errorf("reviewer name is required")
+	}
+	idx := m.categoryIndex(id)
+	if idx < 0 {
+		return fmt.Errorf("category %d does not exist", id)
+	}
+	if reassignTo != nil {
+		if *reassignTo == id {
+			return fmt.Errorf("cannot reassign category %d to itself", id)
+		}
+		if m.categoryIndex(*reassignTo) < 0 {
+			return fmt.Errorf("category %d does not exist", *reassignTo)
+		}
+	}
+
+	index := slices.Clone(m.Index)
+	for i := range index {
+		index[i].Categories = reassignCategory(index[i].Categories, id, reassignTo)
+	}
+	categories := slices.Delete(slices.Clone(m.Categories), idx, idx+1)
+	subject := fmt.Sprintf("Delete category %d", id)
+	if reassignTo != nil {
+		subject = fmt.Sprintf("Merge category %d into %d", id, *reassignTo)
+	}
+	return m.commitCategoryChange(fmt.Sprintf("auditor/category-%d-delete", id), ActionCategoryDelete, id, subject, review, index, categories)
+}
+
+// commitCategoryChange writes index and categories and records the change
+// in the audit log, on its own branch when git is enabled.
+func (m *Manager) commitCategoryChange(branch, action string, id int, subject string, review Review, index []models.IndexEntry, categories []models.Category) error {
+	return m.commitChange(branch, commitMessage(subject, review), dataPaths(), func() error {
+		if err := m.save(index, categories); err != nil {
+			return err
+		}
+		notes := subject
+		if review.Notes != "" {
+			notes = subject + "\n" + review.Notes
+		}
+		return m.AppendAudit(models.AuditRecord{
+			Action:   action,
+			Category: &id,
+			Reviewer: review.Reviewer,
+			Notes:    notes,
+		})
+	})
+}
+
+// CategoryUsage counts the controllers in each category.
+func (m *Manager) CategoryUsage() map[int]int {
+	usage := make(map[int]int)
+	for _, entry := range m.Index {
+		for _, c := range entry.Categories {
+			usage[c]++
+		}
+	}
+	return usage
+}
+
+func (m *Manager) categoryIndex(id int) int {
+	for i, c := range m.Categories {
+		if c.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// reassignCategory replaces from with to in cats, or drops it if to is nil.
+func reassignCategory(cats []int, from int, to *int) []int {
+	out := make([]int, 0, len(cats))
+	hasTo := to != nil && slices.Contains(cats, *to)
+	for _, c := range cats {
+		if c != from {
+			out = append(out, c)
+		} else if to != nil && !hasTo {
+			out = append(out, *to)
+			hasTo = true
+		}
+	}
+	return out
+}
+
+func normalizeTexts(texts []models.LocalizedText) ([]models.LocalizedText, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("at least one locale text is required")
+	}
+	seen := make(map[string]bool)
+	out := make([]models.LocalizedText, 0, len(texts))
+	for _, t := range texts {
+		t.Locale = strings.TrimSpace(t.Locale)
+		t.Text = strings.TrimSpace(t.Text)
+		if t.Locale == "" || t.Text == "" {
+			return nil, fmt.Errorf("locale and text must both be set")
+		}
+		if seen[t.Locale] {
+			return nil, fmt.Errorf("locale %s given more than once", t.Locale)
+		}
+		seen[t.Locale] = true
+		out = append(out, t)
+	}
+	return out, nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+func intPtr(v int) *int { return &v }
+
+func categoryIDs(m *Manager) []int {
+	ids := make([]int, len(m.Categories))
+	for i, c := range m.Categories {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
+func TestCreateCategory(t *testing.T) {
+	texts := []models.LocalizedText{{Locale: "en", Text: "Puzzle"}}
+	tests := []struct {
+		name    string
+		id      *int
+		review  Review
+		wantID  int
+		wantErr bool
+	}{
+		{name: "next free ID", wantID: 3, review: testReview},
+		{name: "explicit ID", id: intPtr(7), wantID: 7, review: testReview},
+		{name: "explicit zero", id: intPtr(0), wantID: 0, review: testReview},
+		{name: "taken ID", id: intPtr(2), review: testReview, wantErr: true},
+		{name: "negative ID", id: intPtr(-1), review: testReview, wantErr: true},
+		{name: "no reviewer", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestRepo(t)
+			cat, err := m.CreateCategory(tt.id, texts, tt.review)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if len(m.Categories) != 2 {
+					t.Errorf("failed create changed categories: %v", categoryIDs(m))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cat.ID != tt.wantID || !slices.Contains(categoryIDs(m), tt.wantID) {
+				t.Errorf("created %d, categories %v, want %d", cat.ID, categoryIDs(m), tt.wantID)
+			}
+			rec := lastAudit(t, m)
+			if rec.Action != ActionCategoryAdd || rec.Category == nil || *rec.Category != tt.wantID || rec.Reviewer != "tester" {
+				t.Errorf("audit record = %+v", rec)
+			}
+		})
+	}
+}
+
+func TestRenameCategory(t *testing.T) {
+	m := newTestRepo(t)
+	texts := []models.LocalizedText{{Locale: "zh", Text: "动作"}}
+	if err := m.RenameCategory(1, texts, testReview); err != nil {
+		t.Fatal(err)
+	}
+	cat := m.Categories[m.categoryIndex(1)]
+	if cat.Text("en") != "Action" || cat.Text("zh") != "动作" {
+		t.Errorf("renamed category = %+v", cat)
+	}
+	if rec := lastAudit(t, m); rec.Action != ActionCategoryRename {
+		t.Errorf("audit record = %+v", rec)
+	}
+	if err := m.RenameCategory(9, texts, testReview); err == nil {
+		t.Error("renaming a missing category should fail")
+	}
+}
+
+func TestDeleteCategory(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       int
+		reassign *int
+		want     map[string][]int
+		wantErr  bool
+	}{
+		{
+			name: "drop",
+			id:   1,
+			want: map[string][]int{"a": {}, "b": {2}},
+		},
+		{
+			name:     "reassign",
+			id:       1,
+			reassign: intPtr(2),
+			want:     map[string][]int{"a": {2}, "b": {2}},
+		},
+		{
+			name:     "reassign to zero",
+			id:       1,
+			reassign: intPtr(0),
+			want:     map[string][]int{"a": {0}, "b": {0, 2}},
+		},
+		{name: "missing category", id: 9, wantErr: true},
+		{name: "missing target", id: 1, reassign: intPtr(9), wantErr: true},
+		{name: "reassign to itself", id: 1, reassign: intPtr(1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestRepo(t)
+			if _, err := m.CreateCategory(intPtr(0), []models.LocalizedText{{Locale: "en", Text: "Other"}}, testReview); err != nil {
+				t.Fatal(err)
+			}
+			addController(t, m, "a", 1)
+			addController(t, m, "b", 1)
+			m.Index[1].Categories = []int{1, 2}
+			if err := m.Save(); err != nil {
+				t.Fatal(err)
+			}
+			before := categoryIDs(m)
+
+			err := m.DeleteCategory(tt.id, tt.reassign, testReview)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if got := categoryIDs(m); !slices.Equal(got, before) {
+					t.Errorf("failed delete changed categories: %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if slices.Contains(categoryIDs(m), tt.id) {
+				t.Errorf("category %d still exists", tt.id)
+			}
+			for _, entry := range m.Index {
+				if !slices.Equal(entry.Categories, tt.want[entry.ID]) {
+					t.Errorf("%s categories = %v, want %v", entry.ID, entry.Categories, tt.want[entry.ID])
+				}
+			}
+			rec := lastAudit(t, m)
+			if rec.Action != ActionCategoryDelete || rec.Category == nil || *rec.Category != tt.id {
+				t.Errorf("audit record = %+v", rec)
+			}
+		})
+	}
+}
+
+func TestMergeCategories(t *testing.T) {
+	m := newTestRepo(t)
+	addController(t, m, "a", 1)
+	if err := m.MergeCategories(1, 1, testReview); err == nil {
+		t.Error("merging a category into itself should fail")
+	}
+	if err := m.MergeCategories(1, 2, Review{}); err == nil {
+		t.Error("merging without a reviewer should fail")
+	}
+	if err := m.MergeCategories(1, 2, testReview); err != nil {
+		t.Fatal(err)
+	}
+	if got := categoryIDs(m); !slices.Equal(got, []int{2}) {
+		t.Errorf("categories = %v, want [2]", got)
+	}
+	if got := m.Index[0].Categories; !slices.Equal(got, []int{2}) {
+		t.Errorf("a categories = %v, want [2]", got)
+	}
+}
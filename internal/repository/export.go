@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+// ExportController writes a controller as a submission ZIP in the layout
+// read by utils.ParseControllerZip:
+//
+//	<id>/index.json
+//	<id>/version.json
+//	<id>/versions/<versionCode>.json
+//	<id>/icon.png
+//	<id>/screenshots/...
+//
+// A versionCode of 0 exports the latest version. For an older version,
+// version.json names it as latest and keeps only the history before it.
+func (m *Manager) ExportController(id string, versionCode int, w io.Writer) error {
+	idx := m.indexOf(id)
+	if idx < 0 {
+		return fmt.Errorf("controller %s is not in index.json", id)
+	}
+	version, err := m.readVersion(id)
+	if err != nil {
+		return fmt.Errorf("controller %s: %v", id, err)
+	}
+	if versionCode != 0 && versionCode != version.Latest.VersionCode {
+		version, err = versionAsOf(version, versionCode)
+		if err != nil {
+			return fmt.Errorf("controller %s: %v", id, err)
+		}
+	}
+	if version.History == nil {
+		version.History = []models.Version{}
+	}
+
+	srcDir := filepath.Join(m.RepoRoot, "repo_json", id)
+	layoutName := fmt.Sprintf("%d.json", version.Latest.VersionCode)
+	layoutPath := filepath.Join(srcDir, "versions", layoutName)
+	if _, err := os.Stat(layoutPath); err != nil {
+		return fmt.Errorf("layout for version %d is missing: %v", version.Latest.VersionCode, err)
+	}
+
+	zw := zip.NewWriter(w)
+	if err := writeZipJSON(zw, path.Join(id, "index.json"), m.Index[idx]); err != nil {
+		return err
+	}
+	if err := writeZipJSON(zw, path.Join(id, "version.json"), version); err != nil {
+		return err
+	}
+	if err := writeZipFile(zw, path.Join(id, "versions", layoutName), layoutPath); err != nil {
+		return err
+	}
+	iconPath := filepath.Join(srcDir, "icon.png")
+	if _, err := os.Stat(iconPath); err == nil {
+		if err := writeZipFile(zw, path.Join(id, "icon.png"), iconPath); err != nil {
+			return err
+		}
+	}
+	for _, s := range utils.ListScreenshots(filepath.Join(srcDir, "screenshots")) {
+		if err := writeZipFile(zw, path.Join(id, "screenshots", filepath.Base(s)), s); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// ExportControllerZip exports a controller into the ZIP file at dest.
+func (m *Manager) ExportControllerZip(id string, versionCode int, dest string) error {
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if err := m.ExportController(id, versionCode, f); err != nil {
+		f.Close()
+		os.Remove(dest)
+		return err
+	}
+	return f.Close()
+}
+
+// versionAsOf returns version.json as it looked when versionCode was the
+// latest version.
+func versionAsOf(version *models.RepoVersion, versionCode int) (*models.RepoVersion, error) {
+	out := *version
+	out.History = []models.Version{}
+	found := false
+	for _, h := range version.History {
+		switch {
+		case h.VersionCode == versionCode:
+			out.Latest = h
+			found = true
+		case h.VersionCode < versionCode:
+			out.History = append(out.History, h)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("version %d is not in the history", versionCode)
+	}
+	return &out, nil
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+func writeZipFile(zw *zip.Writer, name, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, in)
+	return err
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/imaging"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+func TestExportControllerZipRoundTrip(t *testing.T) {
+	m := newTestRepo(t)
+	addController(t, m, "demo", 1, 2, 3)
+	m.Index[0].Translations = []models.LocalizedEntry{{Locale: "zh", Name: "演示", Introduction: "介绍"}}
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(m.RepoRoot, "repo_json", "demo")
+	if err := imaging.WritePNG(filepath.Join(dir, "icon.png"), image.NewNRGBA(image.Rect(0, 0, 64, 64))); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "screenshots"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := imaging.WritePNG(filepath.Join(dir, "screenshots", "1.png"), image.NewNRGBA(image.Rect(0, 0, 16, 9))); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		versionCode int
+		wantLatest  int
+		wantHistory []int
+		wantErr     bool
+	}{
+		{name: "latest", versionCode: 0, wantLatest: 3, wantHistory: []int{1, 2}},
+		{name: "latest by code", versionCode: 3, wantLatest: 3, wantHistory: []int{1, 2}},
+		{name: "older version", versionCode: 2, wantLatest: 2, wantHistory: []int{1}},
+		{name: "oldest version", versionCode: 1, wantLatest: 1, wantHistory: []int{}},
+		{name: "unknown version", versionCode: 9, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "demo.zip")
+			err := m.ExportControllerZip("demo", tt.versionCode, dest)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			pkg, err := utils.ParseControllerZip(dest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer pkg.Cleanup()
+
+			if pkg.ControllerID != "demo" {
+				t.Errorf("ControllerID = %q, want demo", pkg.ControllerID)
+			}
+			if pkg.IndexEntry == nil || !reflect.DeepEqual(*pkg.IndexEntry, m.Index[0]) {
+				t.Errorf("index entry = %+v, want %+v", pkg.IndexEntry, m.Index[0])
+			}
+			if pkg.VersionInfo == nil {
+				t.Fatal("version.json is missing")
+			}
+			if got := pkg.VersionInfo.Latest.VersionCode; got != tt.wantLatest {
+				t.Errorf("latest version = %d, want %d", got, tt.wantLatest)
+			}
+			history := []int{}
+			for _, h := range pkg.VersionInfo.History {
+				history = append(history, h.VersionCode)
+			}
+			if !slices.Equal(history, tt.wantHistory) {
+				t.Errorf("history = %v, want %v", history, tt.wantHistory)
+			}
+			if want := testLayout("demo", tt.wantLatest); !reflect.DeepEqual(pkg.Layout, want) {
+				t.Errorf("layout = %+v, want %+v", pkg.Layout, want)
+			}
+			if pkg.VersionCode != tt.wantLatest {
+				t.Errorf("VersionCode = %d, want %d", pkg.VersionCode, tt.wantLatest)
+			}
+			if pkg.IconPath == "" {
+				t.Error("icon is missing")
+			}
+			if len(pkg.Screenshots) != 1 {
+				t.Errorf("screenshots = %v, want one", pkg.Screenshots)
+			}
+		})
+	}
+}
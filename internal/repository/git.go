@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/vcs"
+)
+
+// EnableGit turns on git integration for the repository root.
+func (m *Manager) EnableGit() error {
+	g, err := vcs.Open(m.RepoRoot)
+	if err != nil {
+		return err
+	}
+	m.Git = g
+	return nil
+}
+
+// DisableGit turns off git integration.
+func (m *Manager) DisableGit() {
+	m.Git = nil
+}
+
+// ControllerHistory returns the git commits that touched repo_json/<id>.
+func (m *Manager) ControllerHistory(id string) ([]vcs.Commit, error) {
+	g := m.Git
+	if g == nil {
+		var err error
+		if g, err = vcs.Open(m.RepoRoot); err != nil {
+			return nil, err
+		}
+	}
+	return g.Log(filepath.ToSlash(filepath.Join("repo_json", id)))
+}
+
+// LastCommitted returns when repo_json/<id> was last committed, or the zero
+// time if it never was.
+func (m *Manager) LastCommitted(id string) (time.Time, error) {
+	g := m.Git
+	if g == nil {
+		var err error
+		if g, err = vcs.Open(m.RepoRoot); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return g.LastCommitDate(filepath.ToSlash(filepath.Join("repo_json", id)))
+}
+
+// commitChange runs change directly when git is disabled. Otherwise it
+// requires a clean tree, runs change on a new branch and commits the changes
+// under paths together with the sidecar files. The starting branch is checked out
+// again afterwards, so the change waits on its branch to be merged; if change
+// or the commit fails, paths and the sidecar files are put back and the branch
+// is deleted. Either way Index and Categories are reloaded from the tree, so
+// change must write its edits to disk instead of making them in memory.
+func (m *Manager) commitChange(branch, message string, paths []string, change func() error) error {
+	if m.Git == nil {
+		err := change()
+		if lerr := m.Load(); lerr != nil {
+			err = errors.Join(err, lerr)
+		}
+		return err
+	}
+	if err := m.requireCleanTree(); err != nil {
+		return err
+	}
+	start, err := m.Git.Head()
+	if err != nil {
+		return err
+	}
+	logBefore, err := readOptional(m.AuditLogPath())
+	if err != nil {
+		return err
+	}
+	branch = m.freeBranchName(branch)
+	if err := m.Git.CreateBranch(branch); err != nil {
+		return err
+	}
+
+	logAfter := logBefore
+	err = change()
+	if err == nil {
+		if logAfter, err = readOptional(m.AuditLogPath()); err == nil {
+			err = m.Git.Commit(message, append(paths, m.sidecarPaths()...)...)
+		}
+	}
+	if err != nil {
+		logAfter = logBefore
+		if derr := m.Git.Discard(append(paths, m.sidecarPaths()...)...); derr != nil {
+			return fmt.Errorf("%v; restoring the working tree also failed: %v", err, derr)
+		}
+	}
+	if cerr := m.Git.Checkout(start); cerr != nil {
+		return errors.Join(err, cerr)
+	}
+	if err != nil {
+		if derr := m.Git.DeleteBranch(branch); derr != nil {
+			err = errors.Join(err, derr)
+		}
+	}
+	// The log is append-only and not tied to a branch: keep the newest copy
+	// in the working tree whichever branch is checked out.
+	if werr := writeOptional(m.AuditLogPath(), logAfter); werr != nil {
+		err = errors.Join(err, werr)
+	}
+	if lerr := m.Load(); lerr != nil {
+		err = errors.Join(err, lerr)
+	}
+	return err
+}
+
+// sidecarPaths lists the files under SidecarDir that are committed with
+// every change: the audit log and, once it exists, the translations.
+func (m *Manager) sidecarPaths() []string {
+	paths := []string{filepath.ToSlash(filepath.Join(SidecarDir, auditLogFile))}
+	if _, err := os.Stat(m.translationsPath()); err == nil {
+		paths = append(paths, filepath.ToSlash(filepath.Join(SidecarDir, translationsFile)))
+	}
+	return paths
+}
+
+// freeBranchName returns branch, or branch with the first free numeric
+// suffix if a branch of that name already exists.
+func (m *Manager) freeBranchName(branch string) string {
+	name := branch
+	for n := 2; m.Git.BranchExists(name); n++ {
+		name = fmt.Sprintf("%s-%d", branch, n)
+	}
+	return name
+}
+
+// dataPaths lists the repository files a change to the given controllers
+// may touch, relative to the repository root.
+func dataPaths(ids ...string) []string {
+	paths := []string{"index.json", "category.json"}
+	for _, id := range ids {
+		paths = append(paths, filepath.ToSlash(filepath.Join("repo_json", id)))
+	}
+	return paths
+}
+
+// readOptional reads a file, returning nil data if it does not exist.
+func readOptional(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// writeOptional writes data to path, or removes path if data is nil.
+func writeOptional(path string, data []byte) error {
+	if data == nil {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// requireCleanTree fails if anything but the sidecar directory has
+// uncommitted changes. Rejections append to the sidecar audit log without a
+// commit of their own; the next committed change picks them up.
+func (m *Manager) requireCleanTree() error {
+	status, err := m.Git.Status(".", ":(exclude)"+SidecarDir)
+	if err != nil {
+		return err
+	}
+	if len(status) > 0 {
+		return fmt.Errorf("git working tree is not clean (%d changed paths); commit or stash them first", len(status))
+	}
+	return nil
+}
+
+func updateBranchName(pkg *utils.ParsedPackage) string {
+	return fmt.Sprintf("auditor/%s-v%d", pkg.ControllerID, pkg.VersionCode)
+}
+
+func updateCommitMessage(pkg *utils.ParsedPackage, oldVersionCode int, review Review) string {
+	versionName := ""
+	if pkg.VersionInfo != nil {
+		versionName = pkg.VersionInfo.Latest.VersionName
+	} else if pkg.Layout != nil {
+		versionName = pkg.Layout.Version
+	}
+
+	var b strings.Builder
+	if oldVersionCode == 0 {
+		fmt.Fprintf(&b, "Add controller %s %s (%d)", pkg.ControllerID, versionName, pkg.VersionCode)
+	} else {
+		fmt.Fprintf(&b, "Update controller %s to %s (%d -> %d)", pkg.ControllerID, versionName, oldVersionCode, pkg.VersionCode)
+	}
+	if pkg.SHA256 != "" {
+		return commitMessage(b.String(), review, "Package-SHA256: "+pkg.SHA256)
+	}
+	return commitMessage(b.String(), review)
+}
+
+// commitMessage appends the reviewer trailer, extra trailers and the
+// reviewer notes to a subject line.
+func commitMessage(subject string, review Review, trailers ...string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\nReviewed-by: %s\n", subject, review.Reviewer)
+	for _, t := range trailers {
+		fmt.Fprintf(&b, "%s\n", t)
+	}
+	if notes := strings.TrimSpace(review.Notes); notes != "" {
+		fmt.Fprintf(&b, "\n%s\n", notes)
+	}
+	return b.String()
+}
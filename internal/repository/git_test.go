@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestGitRepo is newTestRepo committed to a fresh git repository on
+// branch main, with git integration enabled.
+func newTestGitRepo(t *testing.T) *Manager {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "tester")
+	t.Setenv("GIT_AUTHOR_EMAIL", "tester@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "tester")
+	t.Setenv("GIT_COMMITTER_EMAIL", "tester@example.com")
+	m := newTestRepo(t)
+	git(t, m, "init", "-q", "-b", "main")
+	git(t, m, "add", "-A")
+	git(t, m, "commit", "-q", "-m", "initial")
+	if err := m.EnableGit(); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// git runs git in the repository root and returns its trimmed output.
+func git(t *testing.T, m *Manager, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", m.RepoRoot}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestGitApplyCommitsOnSideBranch(t *testing.T) {
+	m := newTestGitRepo(t)
+	if err := m.ApplyUpdate(testPackage(t, "demo"), testReview); err != nil {
+		t.Fatal(err)
+	}
+
+	if head := git(t, m, "rev-parse", "--abbrev-ref", "HEAD"); head != "main" {
+		t.Errorf("checked out %q after the change, want main", head)
+	}
+	if got := git(t, m, "log", "-1", "--format=%s%n%b", "auditor/demo-v1"); !strings.HasPrefix(got, "Add controller demo 1.1 (1)") || !strings.Contains(got, "Reviewed-by: tester") {
+		t.Errorf("branch commit = %q", got)
+	}
+	files := git(t, m, "show", "--name-only", "--format=", "auditor/demo-v1")
+	for _, want := range []string{"index.json", "repo_json/demo/version.json", SidecarDir + "/" + auditLogFile} {
+		if !strings.Contains(files, want) {
+			t.Errorf("commit lacks %s:\n%s", want, files)
+		}
+	}
+	if git(t, m, "rev-list", "--count", "main") != "1" {
+		t.Error("main gained a commit")
+	}
+
+	// The change waits on its branch: main's tree and the reloaded index
+	// don't have it yet, but the audit log kept in the working tree does.
+	if len(m.Index) != 0 {
+		t.Errorf("index on main = %v, want empty", m.IDs())
+	}
+	if _, err := os.Stat(filepath.Join(m.RepoRoot, "repo_json", "demo")); !os.IsNotExist(err) {
+		t.Errorf("repo_json/demo exists on main: %v", err)
+	}
+	if rec := lastAudit(t, m); rec.Action != ActionApply || rec.ControllerID != "demo" {
+		t.Errorf("audit record = %+v", rec)
+	}
+	if status := git(t, m, "status", "--porcelain", "--", ".", ":(exclude)"+SidecarDir); status != "" {
+		t.Errorf("working tree is not clean:\n%s", status)
+	}
+}
+
+func TestGitRefusesDirtyTree(t *testing.T) {
+	m := newTestGitRepo(t)
+	if err := os.WriteFile(filepath.Join(m.RepoRoot, "notes.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := m.ApplyUpdate(testPackage(t, "demo"), testReview)
+	if err == nil || !strings.Contains(err.Error(), "not clean") {
+		t.Fatalf("error = %v, want a dirty tree error", err)
+	}
+	if branches := git(t, m, "branch", "--format=%(refname:short)"); branches != "main" {
+		t.Errorf("branches = %q, want only main", branches)
+	}
+
+	// Sidecar files don't count as changes.
+	if err := os.Remove(filepath.Join(m.RepoRoot, "notes.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RecordRejection(testPackage(t, "demo"), testReview); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ApplyUpdate(testPackage(t, "demo"), testReview); err != nil {
+		t.Errorf("apply with only sidecar changes: %v", err)
+	}
+}
+
+func TestGitBranchNameCollision(t *testing.T) {
+	m := newTestGitRepo(t)
+	git(t, m, "branch", "auditor/demo-v1")
+	git(t, m, "branch", "auditor/demo-v1-2")
+	if err := m.ApplyUpdate(testPackage(t, "demo"), testReview); err != nil {
+		t.Fatal(err)
+	}
+	if got := git(t, m, "rev-list", "--count", "auditor/demo-v1-3"); got != "2" {
+		t.Errorf("auditor/demo-v1-3 has %s commits, want 2", got)
+	}
+	if got := git(t, m, "rev-list", "--count", "auditor/demo-v1"); got != "1" {
+		t.Errorf("existing branch auditor/demo-v1 changed: %s commits", got)
+	}
+}
+
+func TestGitFailedChangeIsDiscarded(t *testing.T) {
+	m := newTestGitRepo(t)
+	if err := m.RecordRejection(testPackage(t, "demo"), testReview); err != nil {
+		t.Fatal(err)
+	}
+	logBefore, err := os.ReadFile(m.AuditLogPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failure := errors.New("change failed")
+	err = m.commitChange("auditor/broken", "broken", dataPaths("demo"), func() error {
+		writeJSON(t, filepath.Join(m.RepoRoot, "index.json"), []string{"garbage"})
+		writeJSON(t, filepath.Join(m.RepoRoot, "repo_json", "demo", "version.json"), map[string]int{})
+		if err := m.RecordRejection(testPackage(t, "demo"), testReview); err != nil {
+			t.Fatal(err)
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("error = %v, want the change's error", err)
+	}
+	if head := git(t, m, "rev-parse", "--abbrev-ref", "HEAD"); head != "main" {
+		t.Errorf("checked out %q, want main", head)
+	}
+	if m.Git.BranchExists("auditor/broken") {
+		t.Error("the failed change's branch was kept")
+	}
+	if status := git(t, m, "status", "--porcelain", "--", ".", ":(exclude)"+SidecarDir); status != "" {
+		t.Errorf("working tree is not clean:\n%s", status)
+	}
+	if len(m.Categories) != 2 {
+		t.Errorf("categories after reload = %v", categoryIDs(m))
+	}
+	logAfter, err := os.ReadFile(m.AuditLogPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(logAfter) != string(logBefore) {
+		t.Errorf("audit log of a failed change was kept:\n%s", logAfter)
+	}
+}
+
+func TestGitAuditLogSurvivesBranchSwitches(t *testing.T) {
+	m := newTestGitRepo(t)
+	if err := m.ApplyUpdate(testPackage(t, "one"), testReview); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ApplyUpdate(testPackage(t, "two"), testReview); err != nil {
+		t.Fatal(err)
+	}
+	records, err := m.AuditLog("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].ControllerID != "one" || records[1].ControllerID != "two" {
+		t.Errorf("audit log = %+v", records)
+	}
+	// Each branch commits the log as it was after its own change.
+	log := git(t, m, "show", "auditor/two-v1:"+SidecarDir+"/"+auditLogFile)
+	if strings.Count(log, "\n")+1 != 2 {
+		t.Errorf("log on auditor/two-v1:\n%s", log)
+	}
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/audit"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+const (
+	ActionRemove      = "remove"
+	ActionDeprecate   = "deprecate"
+	ActionUndeprecate = "undeprecate"
+)
+
+const archiveDir = "archive"
+
+// Referrers returns the IDs of controllers whose deprecation names id as
+// their replacement.
+func (m *Manager) Referrers(id string) []string {
+	var refs []string
+	for _, entry := range m.Index {
+		if entry.Deprecated != nil && entry.Deprecated.ReplacedBy == id {
+			refs = append(refs, entry.ID)
+		}
+	}
+	return refs
+}
+
+// RemoveController drops a controller from index.json. Its repo_json
+// directory is moved into the sidecar archive, or deleted if archive is false.
+func (m *Manager) RemoveController(id string, archive bool, review Review) error {
+	if strings.TrimSpace(review.Reviewer) == "" {
+		return fmt.Errorf("reviewer name is required")
+	}
+	idx := m.indexOf(id)
+	if idx < 0 {
+		return fmt.Errorf("controller %s is not in index.json", id)
+	}
+	if refs := m.Referrers(id); len(refs) > 0 {
+		return fmt.Errorf("controller %s is the replacement of %s; update those first", id, strings.Join(refs, ", "))
+	}
+
+	oldVersionCode := m.latestVersionCode(id)
+	subject := fmt.Sprintf("Remove controller %s", id)
+	return m.commitChange("auditor/"+id+"-remove", commitMessage(subject, review), dataPaths(id), func() error {
+		srcDir := filepath.Join(m.RepoRoot, "repo_json", id)
+		if _, err := os.Stat(srcDir); err == nil {
+			if archive {
+				dest := filepath.Join(m.RepoRoot, SidecarDir, archiveDir, fmt.Sprintf("%s-%s", id, time.Now().UTC().Format("20060102150405")))
+				if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+					return err
+				}
+				if err := os.Rename(srcDir, dest); err != nil {
+					return err
+				}
+			} else if err := os.RemoveAll(srcDir); err != nil {
+				return err
+			}
+		}
+
+		index := slices.Delete(slices.Clone(m.Index), idx, idx+1)
+		if err := m.save(index, m.Categories); err != nil {
+			return err
+		}
+		return m.AppendAudit(models.AuditRecord{
+			Action:         ActionRemove,
+			ControllerID:   id,
+			OldVersionCode: oldVersionCode,
+			Reviewer:       review.Reviewer,
+			Notes:          review.Notes,
+		})
+	})
+}
+
+// DeprecateController marks a controller as deprecated. Controllers that
+// named it as their replacement are re-pointed at replacedBy so chains never
+// end on a deprecated entry.
+func (m *Manager) DeprecateController(id, reason, replacedBy string, review Review) error {
+	if strings.TrimSpace(review.Reviewer) == "" {
+		return fmt.Errorf("reviewer name is required")
+	}
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("a deprecation reason is required")
+	}
+	idx := m.indexOf(id)
+	if idx < 0 {
+		return fmt.Errorf("controller %s is not in index.json", id)
+	}
+	refs := m.Referrers(id)
+	if replacedBy != "" {
+		if replacedBy == id {
+			return fmt.Errorf("controller %s cannot replace itself", id)
+		}
+		r := m.indexOf(replacedBy)
+		if r < 0 {
+			return fmt.Errorf("replacement %s is not in index.json", replacedBy)
+		}
+		if m.Index[r].Deprecated != nil {
+			return fmt.Errorf("replacement %s is itself deprecated", replacedBy)
+		}
+	} else if len(refs) > 0 {
+		return fmt.Errorf("controller %s is the replacement of %s; a new replacement is required", id, strings.Join(refs, ", "))
+	}
+
+	version := m.latestVersionCode(id)
+	subject := fmt.Sprintf("Deprecate controller %s", id)
+	if replacedBy != "" {
+		subject += " in favour of " + replacedBy
+	}
+	return m.commitChange("auditor/"+id+"-deprecate", commitMessage(subject, review), dataPaths(), func() error {
+		date := time.Now().UTC().Format("2006-01-02")
+		index := slices.Clone(m.Index)
+		index[idx].Deprecated = &models.Deprecation{Reason: reason, ReplacedBy: replacedBy, Date: date}
+		for _, ref := range refs {
+			i := m.indexOf(ref)
+			repointed := *index[i].Deprecated
+			repointed.ReplacedBy = replacedBy
+			index[i].Deprecated = &repointed
+		}
+		if err := m.save(index, m.Categories); err != nil {
+			return err
+		}
+		notes := reason
+		if review.Notes != "" {
+			notes = reason + "\n" + review.Notes
+		}
+		return m.AppendAudit(models.AuditRecord{
+			Action:         ActionDeprecate,
+			ControllerID:   id,
+			OldVersionCode: version,
+			NewVersionCode: version,
+			Reviewer:       review.Reviewer,
+			Notes:          notes,
+		})
+	})
+}
+
+// UndeprecateController clears a controller's deprecation.
+func (m *Manager) UndeprecateController(id string, review Review) error {
+	if strings.TrimSpace(review.Reviewer) == "" {
+		return fmt.Errorf("reviewer name is required")
+	}
+	idx := m.indexOf(id)
+	if idx < 0 {
+		return fmt.Errorf("controller %s is not in index.json", id)
+	}
+	if m.Index[idx].Deprecated == nil {
+		return fmt.Errorf("controller %s is not deprecated", id)
+	}
+
+	version := m.latestVersionCode(id)
+	subject := fmt.Sprintf("Restore deprecated controller %s", id)
+	return m.commitChange("auditor/"+id+"-undeprecate", commitMessage(subject, review), dataPaths(), func() error {
+		index := slices.Clone(m.Index)
+		index[idx].Deprecated = nil
+		if err := m.save(index, m.Categories); err != nil {
+			return err
+		}
+		return m.AppendAudit(models.AuditRecord{
+			Action:         ActionUndeprecate,
+			ControllerID:   id,
+			OldVersionCode: version,
+			NewVersionCode: version,
+			Reviewer:       review.Reviewer,
+			Notes:          review.Notes,
+		})
+	})
+}
+
+// CheckConsistency looks for dangling references between index.json,
+// category.json and repo_json. It returns one message per problem.
+func (m *Manager) CheckConsistency() []string {
+	var problems []string
+
+	categories := make(map[int]bool)
+	for _, c := range m.Categories {
+		if categories[c.ID] {
+			problems = append(problems, fmt.Sprintf("category %d: listed more than once in category.json", c.ID))
+		}
+		categories[c.ID] = true
+	}
+	seen := make(map[string]bool)
+	for _, entry := range m.Index {
+		if seen[entry.ID] {
+			problems = append(problems, fmt.Sprintf("%s: listed more than once in index.json", entry.ID))
+		}
+		seen[entry.ID] = true
+
+		version, err := m.readVersion(entry.ID)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: repo_json/%s/version.json is missing or invalid", entry.ID, entry.ID))
+		} else {
+			layout := filepath.Join(m.RepoRoot, "repo_json", entry.ID, "versions", fmt.Sprintf("%d.json", version.Latest.VersionCode))
+			if _, err := os.Stat(layout); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: layout for latest version %d is missing", entry.ID, version.Latest.VersionCode))
+			}
+		}
+
+		for _, c := range entry.Categories {
+			if !categories[c] {
+				problems = append(problems, fmt.Sprintf("%s: unknown category %d", entry.ID, c))
+			}
+		}
+
+		if _, err := m.Suppressions(entry.ID); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: repo_json/%s/%v", entry.ID, entry.ID, err))
+		}
+
+		if entry.Deprecated != nil && entry.Deprecated.ReplacedBy != "" {
+			r := m.indexOf(entry.Deprecated.ReplacedBy)
+			if r < 0 {
+				problems = append(problems, fmt.Sprintf("%s: replacement %s is not in index.json", entry.ID, entry.Deprecated.ReplacedBy))
+			} else if m.Index[r].Deprecated != nil {
+				problems = append(problems, fmt.Sprintf("%s: replacement %s is deprecated", entry.ID, entry.Deprecated.ReplacedBy))
+			}
+		}
+	}
+
+	if dirs, err := os.ReadDir(filepath.Join(m.RepoRoot, "repo_json")); err == nil {
+		for _, d := range dirs {
+			if d.IsDir() && !seen[d.Name()] {
+				problems = append(problems, fmt.Sprintf("%s: repo_json/%s is not listed in index.json", d.Name(), d.Name()))
+			}
+		}
+	}
+
+	sort.Strings(problems)
+	return problems
+}
+
+// Suppressions returns the accepted audit exceptions recorded in
+// repo_json/<id>/audit-ignore.json.
+func (m *Manager) Suppressions(id string) ([]audit.Suppression, error) {
+	return audit.LoadIgnoreFile(filepath.Join(m.RepoRoot, "repo_json", id))
+}
+
+// IDs returns the IDs of every controller in index.json.
+func (m *Manager) IDs() []string {
+	ids := make([]string, len(m.Index))
+	for i, entry := range m.Index {
+		ids[i] = entry.ID
+	}
+	return ids
+}
+
+// Fingerprints fingerprints the latest published layout of every controller
+// in index.json. Controllers whose layout can't be loaded are skipped.
+func (m *Manager) Fingerprints() map[string]audit.Fingerprint {
+	prints := make(map[string]audit.Fingerprint, len(m.Index))
+	for _, entry := range m.Index {
+		pkg, err := m.LoadPackage(entry.ID)
+		if err != nil || pkg.Layout == nil {
+			continue
+		}
+		prints[entry.ID] = audit.NewFingerprint(pkg.Layout)
+	}
+	return prints
+}
+
+func (m *Manager) indexOf(id string) int {
+	for i, entry := range m.Index {
+		if entry.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+func TestRemoveController(t *testing.T) {
+	m := newTestRepo(t)
+	addController(t, m, "a", 1)
+	addController(t, m, "b", 1)
+	addController(t, m, "c", 1)
+	before := m.Index
+
+	if err := m.RemoveController("b", false, testReview); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.IDs(); !slices.Equal(got, []string{"a", "c"}) {
+		t.Errorf("IDs() = %v, want [a c]", got)
+	}
+	if before[1].ID != "b" || before[2].ID != "c" {
+		t.Errorf("removal changed the previous index slice: %v", before)
+	}
+	if _, err := os.Stat(filepath.Join(m.RepoRoot, "repo_json", "b")); !os.IsNotExist(err) {
+		t.Errorf("repo_json/b still exists: %v", err)
+	}
+	if rec := lastAudit(t, m); rec.Action != ActionRemove || rec.ControllerID != "b" {
+		t.Errorf("audit record = %+v", rec)
+	}
+}
+
+func TestDeprecateRepointsReferrers(t *testing.T) {
+	m := newTestRepo(t)
+	addController(t, m, "old", 1)
+	addController(t, m, "mid", 1)
+	addController(t, m, "new", 1)
+
+	if err := m.DeprecateController("old", "superseded", "mid", testReview); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.DeprecateController("mid", "superseded", "new", testReview); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Index[m.indexOf("old")].Deprecated.ReplacedBy; got != "new" {
+		t.Errorf("old is replaced by %q, want new", got)
+	}
+	if err := m.RemoveController("new", false, testReview); err == nil {
+		t.Error("removing a replacement succeeded")
+	}
+	if err := m.UndeprecateController("mid", testReview); err != nil {
+		t.Fatal(err)
+	}
+	if m.Index[m.indexOf("mid")].Deprecated != nil {
+		t.Error("mid is still deprecated")
+	}
+}
+
+func TestFailedChangeKeepsIndex(t *testing.T) {
+	m := newTestRepo(t)
+	addController(t, m, "a", 1)
+	addController(t, m, "b", 1)
+
+	// A directory in place of index.json makes every save fail.
+	indexPath := filepath.Join(m.RepoRoot, "index.json")
+	if err := os.Remove(indexPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(indexPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.DeprecateController("a", "old", "", testReview); err == nil {
+		t.Fatal("DeprecateController succeeded without a writable index")
+	}
+	if m.Index[0].Deprecated != nil {
+		t.Error("failed deprecation changed the index in memory")
+	}
+	if err := m.RemoveController("a", true, testReview); err == nil {
+		t.Fatal("RemoveController succeeded without a writable index")
+	}
+	if got := m.IDs(); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("IDs() = %v after failed removal, want [a b]", got)
+	}
+}
+
+func TestApplyIgnoresSubmittedDeprecation(t *testing.T) {
+	m := newTestRepo(t)
+	ghost := &models.Deprecation{Reason: "self-declared", ReplacedBy: "ghost"}
+
+	pkg := testPackage(t, "demo")
+	pkg.IndexEntry.Deprecated = ghost
+	if err := m.ApplyUpdate(pkg, testReview); err != nil {
+		t.Fatal(err)
+	}
+	if d := m.Index[m.indexOf("demo")].Deprecated; d != nil {
+		t.Errorf("new controller published as deprecated: %+v", d)
+	}
+	if problems := m.CheckConsistency(); len(problems) != 0 {
+		t.Errorf("consistency problems: %v", problems)
+	}
+
+	addController(t, m, "next", 1)
+	if err := m.DeprecateController("demo", "superseded", "next", testReview); err != nil {
+		t.Fatal(err)
+	}
+	for _, submitted := range []*models.Deprecation{nil, ghost} {
+		update := testPackage(t, "demo")
+		update.IsUpdate = true
+		update.IndexEntry.Deprecated = submitted
+		if err := m.ApplyUpdate(update, testReview); err != nil {
+			t.Fatal(err)
+		}
+		if d := m.Index[m.indexOf("demo")].Deprecated; d == nil || d.ReplacedBy != "next" {
+			t.Errorf("submitted %+v: deprecation = %+v, want replaced by next", submitted, d)
+		}
+	}
+}
@@ -3,18 +3,34 @@ package repository
 import (
 	"encoding/json"
 	"fmt"
+	"image"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/imaging"
 	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
 	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/vcs"
 )
 
 type Manager struct {
 	RepoRoot   string
 	Index      []models.IndexEntry
 	Categories []models.Category
+
+	// Git is set when repository changes should be committed to git.
+	Git *vcs.Git
+
+	// DownscaleScreenshots shrinks screenshots larger than the imaging
+	// limits when they are written.
+	DownscaleScreenshots bool
+
+	// NormalizeIcons re-encodes icons to the canonical size on update
+	// instead of copying them as-is.
+	NormalizeIcons bool
 }
 
 func NewManager(repoRoot string) (*Manager, error) {
@@ -25,6 +41,8 @@ func NewManager(repoRoot string) (*Manager, error) {
 	return m, nil
 }
 
+// Load reads index.json and category.json. Index and Categories are only
+// replaced, with fresh slices, once both files have been read.
 func (m *Manager) Load() error {
 	// Load index.json
 	indexPath := filepath.Join(m.RepoRoot, "index.json")
@@ -32,7 +50,11 @@ func (m *Manager) Load() error {
 	if err != nil {
 		return err
 	}
-	if err := json.Unmarshal(iData, &m.Index); err != nil {
+	var index []models.IndexEntry
+	if err := json.Unmarshal(iData, &index); err != nil {
+		return err
+	}
+	if err := m.loadTranslations(index); err != nil {
 		return err
 	}
 
@@ -42,23 +64,45 @@ func (m *Manager) Load() error {
 	if err != nil {
 		return err
 	}
-	if err := json.Unmarshal(cData, &m.Categories); err != nil {
+	var categories []models.Category
+	if err := json.Unmarshal(cData, &categories); err != nil {
 		return err
 	}
 
+	m.Index, m.Categories = index, categories
 	return nil
 }
 
 func (m *Manager) Save() error {
+	return m.save(m.Index, m.Categories)
+}
+
+// save writes index and categories as index.json and category.json, and the
+// index entries' translations to the sidecar.
+func (m *Manager) save(index []models.IndexEntry, categories []models.Category) error {
 	// Save index.json
+	index, translations := splitTranslations(index)
 	indexPath := filepath.Join(m.RepoRoot, "index.json")
-	iData, err := json.MarshalIndent(m.Index, "", "  ")
+	iData, err := json.MarshalIndent(index, "", "  ")
 	if err != nil {
 		return err
 	}
 	if err := os.WriteFile(indexPath, iData, 0644); err != nil {
 		return err
 	}
+	if err := m.saveTranslations(translations); err != nil {
+		return err
+	}
+
+	// Save category.json
+	catPath := filepath.Join(m.RepoRoot, "category.json")
+	cData, err := json.MarshalIndent(categories, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(catPath, cData, 0644); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -75,44 +119,162 @@ func (m *Manager) LoadControllerDetails(id string) (*models.RepoVersion, any, er
 		return nil, nil, err
 	}
 	if err := json.Unmarshal(vData, &version); err != nil {
-		return nil, nil, err
+		return nil, nil, fmt.Errorf("controller %s: version.json: %v", id, err)
 	}
 
 	// Load latest layout
-	layoutPath := filepath.Join(destDir, "versions", fmt.Sprintf("%d.json", version.Latest.VersionCode))
-	lData, err := os.ReadFile(layoutPath)
-	if err != nil {
+	layoutName := fmt.Sprintf("%d.json", version.Latest.VersionCode)
+	lData, err := os.ReadFile(filepath.Join(destDir, "versions", layoutName))
+	if os.IsNotExist(err) {
 		return &version, nil, nil // Layout missing but version exists
 	}
+	if err != nil {
+		return &version, nil, err
+	}
 	var layout any
 	if err := json.Unmarshal(lData, &layout); err != nil {
-		return &version, nil, nil
+		return &version, nil, fmt.Errorf("controller %s: versions/%s: %v", id, layoutName, err)
 	}
 
 	return &version, layout, nil
 }
 
-func (m *Manager) ApplyUpdate(pkg *utils.ParsedPackage) error {
+// LoadPackage builds a package from a controller already in the repository,
+// so it can be audited and edited like a submission.
+func (m *Manager) LoadPackage(id string) (*utils.ParsedPackage, error) {
+	version, layout, err := m.LoadControllerDetails(id)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &utils.ParsedPackage{
+		ControllerID: id,
+		VersionInfo:  version,
+		IsUpdate:     true,
+	}
+
+	if layout != nil {
+		lData, err := json.Marshal(layout)
+		if err != nil {
+			return nil, err
+		}
+		var cl models.ControllerLayout
+		if err := json.Unmarshal(lData, &cl); err != nil {
+			return nil, fmt.Errorf("controller %s: versions/%d.json: %v", id, version.Latest.VersionCode, err)
+		}
+		pkg.Layout = &cl
+		pkg.VersionCode = cl.VersionCode
+	}
+
+	if version != nil && pkg.VersionCode == 0 {
+		pkg.VersionCode = version.Latest.VersionCode
+	}
+
+	if idx := m.indexOf(id); idx >= 0 {
+		entry, current := m.Index[idx], m.Index[idx]
+		pkg.IndexEntry = &entry
+		pkg.CurrentIndex = &current
+	}
+
+	basePath := filepath.Join(m.RepoRoot, "repo_json", id)
+	iconPath := filepath.Join(basePath, "icon.png")
+	if _, err := os.Stat(iconPath); err == nil {
+		pkg.IconPath = iconPath
+	}
+
+	pkg.Screenshots = utils.ListScreenshots(filepath.Join(basePath, "screenshots"))
+	return pkg, nil
+}
+
+// ApplyUpdate writes the package into the repository and records the
+// reviewer's decision in the audit log. With git enabled the working tree
+// must be clean; the update is committed on its own branch, see commitChange.
+func (m *Manager) ApplyUpdate(pkg *utils.ParsedPackage, review Review) error {
+	if strings.TrimSpace(review.Reviewer) == "" {
+		return fmt.Errorf("reviewer name is required")
+	}
+	if err := checkPackageIDs(pkg); err != nil {
+		return err
+	}
+	// Published IDs may predate the naming policy; only new ones must follow it
+	if !pkg.IsUpdate {
+		if err := utils.ValidateControllerID(pkg.ControllerID); err != nil {
+			return err
+		}
+	}
+	oldVersionCode := m.latestVersionCode(pkg.ControllerID)
+	return m.commitChange(updateBranchName(pkg), updateCommitMessage(pkg, oldVersionCode, review), dataPaths(pkg.ControllerID), func() error {
+		return m.applyUpdate(pkg, review)
+	})
+}
+
+// checkPackageIDs requires index.json and the layout to name the package
+// folder's controller, which is the one whose files are written.
+func checkPackageIDs(pkg *utils.ParsedPackage) error {
+	if pkg.IndexEntry != nil && pkg.IndexEntry.ID != pkg.ControllerID {
+		return fmt.Errorf("index.json has ID %q but the package folder is %q", pkg.IndexEntry.ID, pkg.ControllerID)
+	}
+	if pkg.Layout != nil && pkg.Layout.ID != pkg.ControllerID {
+		return fmt.Errorf("layout has ID %q but the package folder is %q", pkg.Layout.ID, pkg.ControllerID)
+	}
+	return nil
+}
+
+func (m *Manager) applyUpdate(pkg *utils.ParsedPackage, review Review) error {
 	destDir := filepath.Join(m.RepoRoot, "repo_json", pkg.ControllerID)
-	
+	oldVersionCode := m.latestVersionCode(pkg.ControllerID)
+
+	// Every image is decoded before anything is written, so a broken file
+	// cannot leave the controller half updated. Screenshots may also live
+	// in the directory being replaced.
+	var icon image.Image
+	if pkg.IconPath != "" {
+		_, img, err := pkg.Image(pkg.IconPath)
+		if err != nil {
+			return fmt.Errorf("icon.png: %v", err)
+		}
+		icon = img
+	}
+	screenshots := make([]image.Image, 0, len(pkg.Screenshots))
+	for _, src := range pkg.Screenshots {
+		_, img, err := pkg.Image(src)
+		if err != nil {
+			return fmt.Errorf("screenshot %s: %v", filepath.Base(src), err)
+		}
+		if m.DownscaleScreenshots {
+			img = imaging.Downscale(img, imaging.ScreenshotMaxWidth, imaging.ScreenshotMaxHeight)
+		}
+		screenshots = append(screenshots, img)
+	}
+
 	// Ensure directory exists
 	if err := os.MkdirAll(destDir, 0755); err != nil {
 		return err
 	}
 
 	// Copy Icon
-	if pkg.IconPath != "" {
-		if err := copyFile(pkg.IconPath, filepath.Join(destDir, "icon.png")); err != nil {
+	if icon != nil {
+		iconDest := filepath.Join(destDir, "icon.png")
+		if m.NormalizeIcons {
+			if err := imaging.NormalizeIcon(icon, iconDest); err != nil {
+				return err
+			}
+		} else if err := copyFile(pkg.IconPath, iconDest); err != nil {
 			return err
 		}
 	}
 
-	// Copy Screenshots
+	// Screenshots are re-encoded as 1.png, 2.png... which drops EXIF and
+	// other metadata.
 	screenshotDest := filepath.Join(destDir, "screenshots")
-	os.RemoveAll(screenshotDest)
-	os.MkdirAll(screenshotDest, 0755)
-	for _, src := range pkg.Screenshots {
-		if err := copyFile(src, filepath.Join(screenshotDest, filepath.Base(src))); err != nil {
+	if err := os.RemoveAll(screenshotDest); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(screenshotDest, 0755); err != nil {
+		return err
+	}
+	for i, img := range screenshots {
+		if err := imaging.WritePNG(filepath.Join(screenshotDest, fmt.Sprintf("%d.png", i+1)), img); err != nil {
 			return err
 		}
 	}
@@ -153,10 +315,7 @@ func (m *Manager) ApplyUpdate(pkg *utils.ParsedPackage) error {
 		finalVersion.Latest = pkg.VersionInfo.Latest
 		finalVersion.Author = pkg.VersionInfo.Author
 		finalVersion.Description = pkg.VersionInfo.Description
-		finalVersion.Screenshot = len(pkg.Screenshots)
-		if pkg.VersionInfo.Screenshot > finalVersion.Screenshot {
-			finalVersion.Screenshot = pkg.VersionInfo.Screenshot
-		}
+		finalVersion.Screenshot = len(screenshots)
 
 		// Merge history from package if any (though usually ZIP only has new version)
 		for _, h := range pkg.VersionInfo.History {
@@ -172,20 +331,32 @@ func (m *Manager) ApplyUpdate(pkg *utils.ParsedPackage) error {
 			}
 		}
 
-		vData, _ := json.MarshalIndent(finalVersion, "", "  ")
-		os.WriteFile(versionPath, vData, 0644)
+		vData, err := json.MarshalIndent(finalVersion, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(versionPath, vData, 0644); err != nil {
+			return err
+		}
 	}
 
 	// Save layout
 	if pkg.Layout != nil {
 		layoutDest := filepath.Join(destDir, "versions")
-		os.MkdirAll(layoutDest, 0755)
-		lData, _ := json.MarshalIndent(pkg.Layout, "", "  ")
+		if err := os.MkdirAll(layoutDest, 0755); err != nil {
+			return err
+		}
+		lData, err := json.MarshalIndent(pkg.Layout, "", "  ")
+		if err != nil {
+			return err
+		}
 		fileName := fmt.Sprintf("%d.json", pkg.VersionCode)
 		if pkg.VersionCode == 0 && pkg.Layout.VersionCode != 0 {
 			fileName = fmt.Sprintf("%d.json", pkg.Layout.VersionCode)
 		}
-		os.WriteFile(filepath.Join(layoutDest, fileName), lData, 0644)
+		if err := os.WriteFile(filepath.Join(layoutDest, fileName), lData, 0644); err != nil {
+			return err
+		}
 	}
 
 	// Ensure version.json exists and has proper structure (history must be an array, not null)
@@ -223,10 +394,7 @@ func (m *Manager) ApplyUpdate(pkg *utils.ParsedPackage) error {
 		finalVersion.Latest = pkg.VersionInfo.Latest
 		finalVersion.Author = pkg.VersionInfo.Author
 		finalVersion.Description = pkg.VersionInfo.Description
-		finalVersion.Screenshot = len(pkg.Screenshots)
-		if pkg.VersionInfo.Screenshot > finalVersion.Screenshot {
-			finalVersion.Screenshot = pkg.VersionInfo.Screenshot
-		}
+		finalVersion.Screenshot = len(screenshots)
 
 		// Merge history from package if any
 		for _, h := range pkg.VersionInfo.History {
@@ -249,33 +417,87 @@ func (m *Manager) ApplyUpdate(pkg *utils.ParsedPackage) error {
 				VersionName: pkg.Layout.Version,
 			}
 		}
-		// Ensure screenshot count is at least current screenshots
-		if finalVersion.Screenshot < len(pkg.Screenshots) {
-			finalVersion.Screenshot = len(pkg.Screenshots)
-		}
+		// The screenshot count always matches the files written above
+		finalVersion.Screenshot = len(screenshots)
 	}
 
 	// Write back normalized version.json
-	if vData, err := json.MarshalIndent(finalVersion, "", "  "); err == nil {
-		_ = os.WriteFile(versionPath, vData, 0644)
+	vData, err := json.MarshalIndent(finalVersion, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(versionPath, vData, 0644); err != nil {
+		return err
 	}
 
-	// Update index.json in memory
+	// Update index.json; commitChange reloads the index from disk
+	index := slices.Clone(m.Index)
 	if pkg.IndexEntry != nil {
 		found := false
-		for i, entry := range m.Index {
-			if entry.ID == pkg.IndexEntry.ID {
-				m.Index[i] = *pkg.IndexEntry
+		for i, entry := range index {
+			if entry.ID == pkg.ControllerID {
+				updated := *pkg.IndexEntry
+				// Deprecation is repository state, not part of a submission
+				updated.Deprecated = entry.Deprecated
+				index[i] = updated
 				found = true
 				break
 			}
 		}
 		if !found {
-			m.Index = append(m.Index, *pkg.IndexEntry)
+			added := *pkg.IndexEntry
+			added.Deprecated = nil
+			index = append(index, added)
 		}
 	}
 
-	return m.Save()
+	if err := m.save(index, m.Categories); err != nil {
+		return err
+	}
+
+	return m.AppendAudit(models.AuditRecord{
+		Action:         ActionApply,
+		ControllerID:   pkg.ControllerID,
+		OldVersionCode: oldVersionCode,
+		NewVersionCode: finalVersion.Latest.VersionCode,
+		Reviewer:       review.Reviewer,
+		PackageSHA256:  pkg.SHA256,
+		Findings:       review.Findings,
+		Notes:          review.Notes,
+	})
+}
+
+// latestVersionCode returns the published versionCode of a controller, or 0
+// if it has no version.json yet.
+func (m *Manager) latestVersionCode(id string) int {
+	version, err := m.readVersion(id)
+	if err != nil {
+		return 0
+	}
+	return version.Latest.VersionCode
+}
+
+func (m *Manager) readVersion(id string) (*models.RepoVersion, error) {
+	vData, err := os.ReadFile(filepath.Join(m.RepoRoot, "repo_json", id, "version.json"))
+	if err != nil {
+		return nil, err
+	}
+	var version models.RepoVersion
+	if err := json.Unmarshal(vData, &version); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+func (m *Manager) writeVersion(id string, version *models.RepoVersion) error {
+	if version.History == nil {
+		version.History = []models.Version{}
+	}
+	vData, err := json.MarshalIndent(version, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.RepoRoot, "repo_json", id, "version.json"), vData, 0644)
 }
 
 func copyFile(src, dst string) error {
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPackageReportsBrokenFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{"invalid layout JSON", "versions/1.json", "{", "versions/1.json"},
+		{"layout of the wrong shape", "versions/1.json", `{"viewGroups": "none"}`, "versions/1.json"},
+		{"invalid version.json", "version.json", "[", "version.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestRepo(t)
+			addController(t, m, "demo", 1)
+			path := filepath.Join(m.RepoRoot, "repo_json", "demo", tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := m.LoadPackage("demo")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadPackage error = %v, want one naming %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPackageWithoutLayout(t *testing.T) {
+	m := newTestRepo(t)
+	addController(t, m, "demo", 1)
+	if err := removeLayout(m, "demo", 1); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := m.LoadPackage("demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.Layout != nil || pkg.VersionCode != 1 {
+		t.Errorf("package = %+v, want no layout and version 1", pkg)
+	}
+}
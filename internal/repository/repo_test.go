@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+var testReview = Review{Reviewer: "tester", Notes: "ok"}
+
+// newTestRepo writes an empty repository with two categories into a
+// temporary directory and opens it.
+func newTestRepo(t *testing.T) *Manager {
+	t.Helper()
+	root := t.TempDir()
+	writeJSON(t, filepath.Join(root, "index.json"), []models.IndexEntry{})
+	writeJSON(t, filepath.Join(root, "category.json"), []models.Category{
+		{ID: 1, Lang: []models.LocalizedText{{Locale: "en", Text: "Action"}}},
+		{ID: 2, Lang: []models.LocalizedText{{Locale: "en", Text: "Racing"}}},
+	})
+	m, err := NewManager(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// addController publishes id with a layout for every version code; the last
+// code is the latest version.
+func addController(t *testing.T, m *Manager, id string, codes ...int) {
+	t.Helper()
+	dir := filepath.Join(m.RepoRoot, "repo_json", id)
+	version := models.RepoVersion{Author: "alice", History: []models.Version{}}
+	for i, code := range codes {
+		v := models.Version{VersionCode: code, VersionName: fmt.Sprintf("1.%d", code)}
+		if i == len(codes)-1 {
+			version.Latest = v
+		} else {
+			version.History = append(version.History, v)
+		}
+		writeJSON(t, filepath.Join(dir, "versions", fmt.Sprintf("%d.json", code)), testLayout(id, code))
+	}
+	writeJSON(t, filepath.Join(dir, "version.json"), version)
+	m.Index = append(m.Index, models.IndexEntry{
+		ID:         id,
+		Lang:       "en",
+		Name:       id,
+		Device:     []models.Device{models.DevicePhone},
+		Categories: []int{1},
+	})
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testLayout(id string, code int) *models.ControllerLayout {
+	return &models.ControllerLayout{
+		ID:          id,
+		Name:        id,
+		Version:     fmt.Sprintf("1.%d", code),
+		VersionCode: code,
+		ButtonStyles: []models.ButtonStyle{
+			{Name: "Default", FillColor: 0x7f000000, StrokeColor: -16777216, TextColor: -1},
+		},
+		DirectionStyles: []models.DirectionStyle{},
+		ViewGroups: []models.ViewGroup{{
+			ID:         "g1",
+			Name:       "Main",
+			Visibility: models.VisibilityShown,
+			ViewData: models.ViewData{
+				ButtonList: []models.Button{{
+					ID:    "b1",
+					Text:  "A",
+					Style: "Default",
+					BaseInfo: models.BaseInfo{
+						XPosition: 100, YPosition: 600,
+						SizeType:      models.SizeAbsolute,
+						AbsoluteWidth: 60, AbsoluteHeight: 60,
+					},
+				}},
+				DirectionList: []models.Direction{},
+			},
+		}},
+	}
+}
+
+func writeJSON(t *testing.T, path string, v any) {
+	t.Helper()
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// lastAudit returns the newest audit log record.
+func lastAudit(t *testing.T, m *Manager) models.AuditRecord {
+	t.Helper()
+	records, err := m.AuditLog("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) == 0 {
+		t.Fatal("audit log is empty")
+	}
+	return records[len(records)-1]
+}
+
+func removeLayout(m *Manager, id string, code int) error {
+	return os.Remove(filepath.Join(m.RepoRoot, "repo_json", id, "versions", fmt.Sprintf("%d.json", code)))
+}
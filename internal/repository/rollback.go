@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+const ActionRollback = "rollback"
+
+// Rollback promotes a historical version of a controller back to Latest.
+// The demoted version is kept in History so it can be restored later.
+func (m *Manager) Rollback(id string, versionCode int, review Review) error {
+	if strings.TrimSpace(review.Reviewer) == "" {
+		return fmt.Errorf("reviewer name is required")
+	}
+	version, err := m.readVersion(id)
+	if err != nil {
+		return fmt.Errorf("controller %s: %v", id, err)
+	}
+	if version.Latest.VersionCode == versionCode {
+		return fmt.Errorf("version %d of %s is already the latest", versionCode, id)
+	}
+
+	idx := -1
+	for i, h := range version.History {
+		if h.VersionCode == versionCode {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return fmt.Errorf("version %d is not in the history of %s", versionCode, id)
+	}
+	layoutPath := filepath.Join(m.RepoRoot, "repo_json", id, "versions", fmt.Sprintf("%d.json", versionCode))
+	if _, err := os.Stat(layoutPath); err != nil {
+		return fmt.Errorf("layout for version %d is missing: %v", versionCode, err)
+	}
+
+	target := version.History[idx]
+	demoted := version.Latest
+	history := make([]models.Version, 0, len(version.History))
+	history = append(history, version.History[:idx]...)
+	history = append(history, version.History[idx+1:]...)
+	if demoted.VersionCode != 0 {
+		history = append(history, demoted)
+	}
+	version.Latest = target
+	version.History = history
+
+	branch := fmt.Sprintf("auditor/%s-rollback-v%d", id, versionCode)
+	subject := fmt.Sprintf("Roll back controller %s to %s (%d -> %d)", id, target.VersionName, demoted.VersionCode, target.VersionCode)
+	return m.commitChange(branch, commitMessage(subject, review), dataPaths(id), func() error {
+		if err := m.writeVersion(id, version); err != nil {
+			return err
+		}
+		return m.AppendAudit(models.AuditRecord{
+			Action:         ActionRollback,
+			ControllerID:   id,
+			OldVersionCode: demoted.VersionCode,
+			NewVersionCode: target.VersionCode,
+			Reviewer:       review.Reviewer,
+			Findings:       review.Findings,
+			Notes:          review.Notes,
+		})
+	})
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRollback(t *testing.T) {
+	tests := []struct {
+		name        string
+		versionCode int
+		review      Review
+		removeFile  bool
+		wantErr     string
+	}{
+		{name: "restores history version", versionCode: 2, review: testReview},
+		{name: "already latest", versionCode: 3, review: testReview, wantErr: "already the latest"},
+		{name: "not in history", versionCode: 9, review: testReview, wantErr: "not in the history"},
+		{name: "layout missing", versionCode: 1, review: testReview, removeFile: true, wantErr: "is missing"},
+		{name: "reviewer required", versionCode: 2, wantErr: "reviewer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestRepo(t)
+			addController(t, m, "pad", 1, 2, 3)
+			if tt.removeFile {
+				if err := removeLayout(m, "pad", 1); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := m.Rollback("pad", tt.versionCode, tt.review)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Rollback() error = %v, want %q", err, tt.wantErr)
+				}
+				if v, _ := m.readVersion("pad"); v.Latest.VersionCode != 3 {
+					t.Errorf("latest = %d after failed rollback, want 3", v.Latest.VersionCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			v, err := m.readVersion("pad")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.Latest.VersionCode != 2 {
+				t.Errorf("latest = %d, want 2", v.Latest.VersionCode)
+			}
+			var history []int
+			for _, h := range v.History {
+				history = append(history, h.VersionCode)
+			}
+			if len(history) != 2 || history[0] != 1 || history[1] != 3 {
+				t.Errorf("history = %v, want [1 3]", history)
+			}
+			rec := lastAudit(t, m)
+			if rec.Action != ActionRollback || rec.OldVersionCode != 3 || rec.NewVersionCode != 2 {
+				t.Errorf("audit record = %+v", rec)
+			}
+		})
+	}
+}
+
+func TestRollbackCanBeReverted(t *testing.T) {
+	m := newTestRepo(t)
+	addController(t, m, "pad", 1, 2)
+	if err := m.Rollback("pad", 1, testReview); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Rollback("pad", 2, testReview); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := m.readVersion("pad"); v.Latest.VersionCode != 2 || len(v.History) != 1 || v.History[0].VersionCode != 1 {
+		t.Errorf("version = %+v, want latest 2 and history [1]", v)
+	}
+}
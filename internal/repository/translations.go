@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+// translationsFile holds the names and introductions of controllers in
+// locales other than their primary one, keyed by controller ID. The client
+// only reads index.json, so they stay in the sidecar.
+const translationsFile = "translations.json"
+
+func (m *Manager) translationsPath() string {
+	return filepath.Join(m.RepoRoot, SidecarDir, translationsFile)
+}
+
+// loadTranslations sets the Translations of every entry in index from the
+// sidecar file, if there is one.
+func (m *Manager) loadTranslations(index []models.IndexEntry) error {
+	data, err := readOptional(m.translationsPath())
+	if err != nil || data == nil {
+		return err
+	}
+	var byID map[string][]models.LocalizedEntry
+	if err := json.Unmarshal(data, &byID); err != nil {
+		return fmt.Errorf("%s: %v", filepath.Join(SidecarDir, translationsFile), err)
+	}
+	for i := range index {
+		index[i].Translations = byID[index[i].ID]
+	}
+	return nil
+}
+
+// splitTranslations returns index without Translations, as index.json stores
+// it, and the translations by controller ID.
+func splitTranslations(index []models.IndexEntry) ([]models.IndexEntry, map[string][]models.LocalizedEntry) {
+	stripped := make([]models.IndexEntry, len(index))
+	byID := make(map[string][]models.LocalizedEntry)
+	for i, entry := range index {
+		if len(entry.Translations) > 0 {
+			byID[entry.ID] = entry.Translations
+		}
+		entry.Translations = nil
+		stripped[i] = entry
+	}
+	return stripped, byID
+}
+
+// saveTranslations writes byID to the sidecar file. No file is created for
+// a repository without translations.
+func (m *Manager) saveTranslations(byID map[string][]models.LocalizedEntry) error {
+	path := m.translationsPath()
+	if len(byID) == 0 {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil
+		}
+	}
+	data, err := json.MarshalIndent(byID, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
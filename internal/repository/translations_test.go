@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+func TestTranslationsStayOutOfIndex(t *testing.T) {
+	m := newTestRepo(t)
+	addController(t, m, "a", 1)
+	addController(t, m, "b", 1)
+	zh := []models.LocalizedEntry{{Locale: "zh", Name: "甲", Introduction: "介绍"}}
+	m.Index[0].Translations = zh
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(m.RepoRoot, "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "translations") {
+		t.Errorf("index.json contains translations:\n%s", data)
+	}
+
+	reloaded, err := NewManager(m.RepoRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.Index[0].Translations; !slices.Equal(got, zh) {
+		t.Errorf("a translations = %v, want %v", got, zh)
+	}
+	if got := reloaded.Index[1].Translations; got != nil {
+		t.Errorf("b translations = %v, want none", got)
+	}
+}
+
+func TestNoTranslationsFileWithoutTranslations(t *testing.T) {
+	m := newTestRepo(t)
+	addController(t, m, "a", 1)
+	if _, err := os.Stat(m.translationsPath()); !os.IsNotExist(err) {
+		t.Errorf("translations file was created: %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+// maxHistory bounds the undo stack.
+const maxHistory = 100
+
+// snapshot is a deep copy of everything a reviewer can edit in a package.
+// The JSON documents are stored encoded so later edits cannot alias them.
+type snapshot struct {
+	index       []byte
+	version     []byte
+	layout      []byte
+	versionCode int
+	iconPath    string
+	screenshots []string
+}
+
+func takeSnapshot(pkg *utils.ParsedPackage) (snapshot, error) {
+	sn := snapshot{
+		versionCode: pkg.VersionCode,
+		iconPath:    pkg.IconPath,
+		screenshots: append([]string(nil), pkg.Screenshots...),
+	}
+	var err error
+	if sn.index, err = json.Marshal(pkg.IndexEntry); err != nil {
+		return sn, err
+	}
+	if sn.version, err = json.Marshal(pkg.VersionInfo); err != nil {
+		return sn, err
+	}
+	if sn.layout, err = json.Marshal(pkg.Layout); err != nil {
+		return sn, err
+	}
+	return sn, nil
+}
+
+func (sn snapshot) equal(other snapshot) bool {
+	return bytes.Equal(sn.index, other.index) &&
+		bytes.Equal(sn.version, other.version) &&
+		bytes.Equal(sn.layout, other.layout) &&
+		sn.versionCode == other.versionCode &&
+		sn.iconPath == other.iconPath &&
+		slices.Equal(sn.screenshots, other.screenshots)
+}
+
+func (sn snapshot) restore(pkg *utils.ParsedPackage) error {
+	var index *models.IndexEntry
+	var version *models.RepoVersion
+	var layout *models.ControllerLayout
+	if err := json.Unmarshal(sn.index, &index); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(sn.version, &version); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(sn.layout, &layout); err != nil {
+		return err
+	}
+	pkg.IndexEntry = index
+	pkg.VersionInfo = version
+	pkg.Layout = layout
+	pkg.VersionCode = sn.versionCode
+	pkg.IconPath = sn.iconPath
+	pkg.Screenshots = append([]string(nil), sn.screenshots...)
+	return nil
+}
+
+// EditState tells a front end which history actions are available.
+type EditState struct {
+	CanUndo  bool `json:"canUndo"`
+	CanRedo  bool `json:"canRedo"`
+	Modified bool `json:"modified"`
+}
+
+// resetHistory forgets every edit and records the current package as the
+// submitted state.
+func (s *Session) resetHistory() {
+	s.undo, s.redo = nil, nil
+	s.submitted = snapshot{}
+	s.hasSubmitted = false
+	if s.pkg == nil {
+		return
+	}
+	if sn, err := takeSnapshot(s.pkg); err == nil {
+		s.submitted = sn
+		s.hasSubmitted = true
+	}
+}
+
+// edit runs fn on the current package as one undoable step. If fn fails the
+// package is put back the way it was.
+func (s *Session) edit(fn func(pkg *utils.ParsedPackage) error) error {
+	pkg, err := s.current()
+	if err != nil {
+		return err
+	}
+	before, err := takeSnapshot(pkg)
+	if err != nil {
+		return err
+	}
+	if err := fn(pkg); err != nil {
+		before.restore(pkg)
+		return err
+	}
+	s.undo = append(s.undo, before)
+	if len(s.undo) > maxHistory {
+		s.undo = s.undo[len(s.undo)-maxHistory:]
+	}
+	s.redo = nil
+	s.audit()
+	return nil
+}
+
+// Undo reverts the most recent edit.
+func (s *Session) Undo() error {
+	return s.step(&s.undo, &s.redo)
+}
+
+// Redo re-applies the most recently undone edit.
+func (s *Session) Redo() error {
+	return s.step(&s.redo, &s.undo)
+}
+
+// step pops a snapshot from one stack, pushing the current state onto the
+// other.
+func (s *Session) step(from, to *[]snapshot) error {
+	pkg, err := s.current()
+	if err != nil {
+		return err
+	}
+	if len(*from) == 0 {
+		return fmt.Errorf("nothing to restore")
+	}
+	now, err := takeSnapshot(pkg)
+	if err != nil {
+		return err
+	}
+	target := (*from)[len(*from)-1]
+	if err := target.restore(pkg); err != nil {
+		return err
+	}
+	*from = (*from)[:len(*from)-1]
+	*to = append(*to, now)
+	s.audit()
+	return nil
+}
+
+// ResetToSubmitted restores the values the package was loaded with. The
+// reset itself can be undone.
+func (s *Session) ResetToSubmitted() error {
+	if !s.hasSubmitted {
+		return ErrNoPackage
+	}
+	return s.edit(func(pkg *utils.ParsedPackage) error {
+		return s.submitted.restore(pkg)
+	})
+}
+
+// EditState reports whether undo, redo and reset are currently meaningful.
+func (s *Session) EditState() EditState {
+	state := EditState{
+		CanUndo: len(s.undo) > 0,
+		CanRedo: len(s.redo) > 0,
+	}
+	if s.pkg != nil && s.hasSubmitted {
+		if now, err := takeSnapshot(s.pkg); err == nil {
+			state.Modified = !now.equal(s.submitted)
+		}
+	}
+	return state
+}
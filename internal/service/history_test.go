@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+// rename edits the layout name of the package under review.
+func rename(t *testing.T, s *Session, name string) {
+	t.Helper()
+	if err := s.edit(func(pkg *utils.ParsedPackage) error { pkg.Layout.Name = name; return nil }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func layoutName(s *Session) string {
+	return s.Package().Layout.Name
+}
+
+func TestUndoRedo(t *testing.T) {
+	s := newTestSession(t)
+	s.SetPackage(testPackage(t, "demo"))
+	if st := s.EditState(); st.CanUndo || st.CanRedo || st.Modified {
+		t.Errorf("fresh package state = %+v", st)
+	}
+	if err := s.Undo(); err == nil {
+		t.Error("undo with no edits succeeded")
+	}
+
+	rename(t, s, "one")
+	rename(t, s, "two")
+	if st := s.EditState(); !st.CanUndo || st.CanRedo || !st.Modified {
+		t.Errorf("state after edits = %+v", st)
+	}
+
+	if err := s.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := layoutName(s); got != "one" {
+		t.Errorf("after undo name = %q, want one", got)
+	}
+	if err := s.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := layoutName(s); got != "demo" {
+		t.Errorf("after second undo name = %q, want demo", got)
+	}
+	if st := s.EditState(); st.CanUndo || !st.CanRedo || st.Modified {
+		t.Errorf("state after undoing everything = %+v", st)
+	}
+
+	if err := s.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := layoutName(s); got != "two" {
+		t.Errorf("after redo name = %q, want two", got)
+	}
+	if err := s.Redo(); err == nil {
+		t.Error("redo past the newest edit succeeded")
+	}
+}
+
+func TestEditClearsRedo(t *testing.T) {
+	s := newTestSession(t)
+	s.SetPackage(testPackage(t, "demo"))
+	rename(t, s, "one")
+	if err := s.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	rename(t, s, "other")
+	if s.EditState().CanRedo {
+		t.Error("redo still available after a new edit")
+	}
+	if err := s.Redo(); err == nil {
+		t.Error("redo after a new edit succeeded")
+	}
+	if got := layoutName(s); got != "other" {
+		t.Errorf("name = %q, want other", got)
+	}
+}
+
+func TestFailedEditIsNotRecorded(t *testing.T) {
+	s := newTestSession(t)
+	s.SetPackage(testPackage(t, "demo"))
+	failure := errors.New("failed")
+	err := s.edit(func(pkg *utils.ParsedPackage) error {
+		pkg.Layout.Name = "half done"
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("error = %v", err)
+	}
+	if got := layoutName(s); got != "demo" {
+		t.Errorf("name = %q, want the edit rolled back", got)
+	}
+	if s.EditState().CanUndo {
+		t.Error("a failed edit can be undone")
+	}
+}
+
+func TestResetToSubmitted(t *testing.T) {
+	s := newTestSession(t)
+	if err := s.ResetToSubmitted(); err == nil {
+		t.Error("reset without a package succeeded")
+	}
+	s.SetPackage(testPackage(t, "demo"))
+	rename(t, s, "one")
+	rename(t, s, "two")
+
+	if err := s.ResetToSubmitted(); err != nil {
+		t.Fatal(err)
+	}
+	if got := layoutName(s); got != "demo" {
+		t.Errorf("after reset name = %q, want demo", got)
+	}
+	if st := s.EditState(); st.Modified || !st.CanUndo {
+		t.Errorf("state after reset = %+v", st)
+	}
+
+	// The reset is itself an edit.
+	if err := s.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := layoutName(s); got != "two" {
+		t.Errorf("after undoing the reset name = %q, want two", got)
+	}
+
+	// Loading another package starts a new history.
+	s.SetPackage(testPackage(t, "next"))
+	if st := s.EditState(); st.CanUndo || st.CanRedo || st.Modified {
+		t.Errorf("state after loading a package = %+v", st)
+	}
+}
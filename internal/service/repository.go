@@ -0,0 +1,102 @@
+package service
+
+import (
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/repository"
+)
+
+// The methods below change the open repository. Going through the session
+// instead of the repository.Manager keeps the package under review audited
+// against the current index and published layouts.
+
+// Rollback promotes an older version of controller id back to latest.
+func (s *Session) Rollback(id string, versionCode int, review repository.Review) error {
+	mgr, err := s.Repo()
+	if err != nil {
+		return err
+	}
+	err = mgr.Rollback(id, versionCode, review)
+	s.changed()
+	return err
+}
+
+// RemoveController removes controller id from the repository, archiving its
+// files if archive is set.
+func (s *Session) RemoveController(id string, archive bool, review repository.Review) error {
+	mgr, err := s.Repo()
+	if err != nil {
+		return err
+	}
+	err = mgr.RemoveController(id, archive, review)
+	s.changed()
+	return err
+}
+
+// DeprecateController marks controller id deprecated, optionally naming its
+// replacement.
+func (s *Session) DeprecateController(id, reason, replacedBy string, review repository.Review) error {
+	mgr, err := s.Repo()
+	if err != nil {
+		return err
+	}
+	err = mgr.DeprecateController(id, reason, replacedBy, review)
+	s.changed()
+	return err
+}
+
+// UndeprecateController clears the deprecation of controller id.
+func (s *Session) UndeprecateController(id string, review repository.Review) error {
+	mgr, err := s.Repo()
+	if err != nil {
+		return err
+	}
+	err = mgr.UndeprecateController(id, review)
+	s.changed()
+	return err
+}
+
+// CreateCategory adds a category; a nil id picks the next free ID.
+func (s *Session) CreateCategory(id *int, texts []models.LocalizedText, review repository.Review) (models.Category, error) {
+	mgr, err := s.Repo()
+	if err != nil {
+		return models.Category{}, err
+	}
+	cat, err := mgr.CreateCategory(id, texts, review)
+	s.changed()
+	return cat, err
+}
+
+// RenameCategory updates the names of category id.
+func (s *Session) RenameCategory(id int, texts []models.LocalizedText, review repository.Review) error {
+	mgr, err := s.Repo()
+	if err != nil {
+		return err
+	}
+	err = mgr.RenameCategory(id, texts, review)
+	s.changed()
+	return err
+}
+
+// MergeCategories moves the controllers of category from into category into
+// and deletes from.
+func (s *Session) MergeCategories(from, into int, review repository.Review) error {
+	mgr, err := s.Repo()
+	if err != nil {
+		return err
+	}
+	err = mgr.MergeCategories(from, into, review)
+	s.changed()
+	return err
+}
+
+// DeleteCategory removes category id, moving its controllers to reassignTo
+// unless it is nil.
+func (s *Session) DeleteCategory(id int, reassignTo *int, review repository.Review) error {
+	mgr, err := s.Repo()
+	if err != nil {
+		return err
+	}
+	err = mgr.DeleteCategory(id, reassignTo, review)
+	s.changed()
+	return err
+}
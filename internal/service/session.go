@@ -0,0 +1,505 @@
+// Package service holds the review session shared by the Wails bindings,
+// the Fyne front end and the CLI, so that every front end opens
+// repositories, loads packages and applies updates the same way.
+package service
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"slices"
+	"strings"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/audit"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/report"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/repository"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+var (
+	ErrNoRepository = errors.New("repository not selected")
+	ErrNoPackage    = errors.New("package not selected")
+)
+
+// Session is the state of one reviewer's work: the open repository, the
+// package under review, its latest audit findings and the edit history.
+//
+// A Session is not safe for concurrent use. The Wails app serializes its
+// calls, the Fyne front end only calls it from the UI goroutine and every
+// CLI command runs on a single goroutine.
+type Session struct {
+	manager  *repository.Manager
+	pkg      *utils.ParsedPackage
+	findings []audit.Finding
+
+	undo, redo   []snapshot
+	submitted    snapshot
+	hasSubmitted bool
+
+	profiles      *audit.ProfileSet
+	profile       *audit.Profile
+	disabledRules []string
+
+	// prints caches the fingerprints of the published layouts; nil until
+	// first needed and after the repository changes.
+	prints map[string]audit.Fingerprint
+	// similar caches the matches of similarPrint, the fingerprint of
+	// controller similarID, against prints. Edits that leave the fingerprint
+	// alone reuse them instead of comparing with every published layout.
+	similar      []audit.Match
+	similarPrint audit.Fingerprint
+	similarID    string
+
+	// PreviewWidth and PreviewHeight size the layout preview in rejection
+	// reports; zero keeps models.PreviewWidth and models.PreviewHeight.
+	PreviewWidth, PreviewHeight int
+}
+
+// New returns a session with no repository or package selected.
+func New() *Session {
+	return &Session{}
+}
+
+// OpenRepo makes the repository at root the session's repository, selects
+// its default audit profile and re-audits the current package against it.
+func (s *Session) OpenRepo(root string) (*repository.Manager, error) {
+	mgr, err := repository.NewManager(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository: %v", err)
+	}
+	profiles, err := audit.LoadProfiles(root)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := profiles.Profile("")
+	if err != nil {
+		return nil, err
+	}
+	s.manager = mgr
+	s.profiles, s.profile = profiles, profile
+	s.changed()
+	return mgr, nil
+}
+
+// Manager returns the open repository, or nil if none is selected.
+func (s *Session) Manager() *repository.Manager {
+	return s.manager
+}
+
+// Repo returns the open repository, or ErrNoRepository.
+func (s *Session) Repo() (*repository.Manager, error) {
+	if s.manager == nil {
+		return nil, ErrNoRepository
+	}
+	return s.manager, nil
+}
+
+// Package returns the package under review, or nil.
+func (s *Session) Package() *utils.ParsedPackage {
+	return s.pkg
+}
+
+// Findings returns the audit findings for the package under review.
+func (s *Session) Findings() []audit.Finding {
+	return s.findings
+}
+
+// OpenZip parses a submission ZIP and makes it the package under review.
+func (s *Session) OpenZip(path string) (*utils.ParsedPackage, error) {
+	pkg, err := utils.ParseControllerZip(path)
+	if err != nil {
+		return nil, err
+	}
+	s.SetPackage(pkg)
+	return pkg, nil
+}
+
+// OpenLayout starts a new package from a bare layout JSON.
+func (s *Session) OpenLayout(path string) (*utils.ParsedPackage, error) {
+	pkg, err := utils.NewPackageFromLayout(path)
+	if err != nil {
+		return nil, err
+	}
+	s.SetPackage(pkg)
+	return pkg, nil
+}
+
+// LoadController loads a published controller from the repository for review.
+func (s *Session) LoadController(id string) (*utils.ParsedPackage, error) {
+	mgr, err := s.Repo()
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := mgr.LoadPackage(id)
+	if err != nil {
+		return nil, err
+	}
+	s.SetPackage(pkg)
+	return pkg, nil
+}
+
+// SetPackage makes pkg the package under review, marking it as an update if
+// the controller is already in the repository. Unless pkg is already under
+// review, the previous package's temporary files are removed and the edit
+// history restarts.
+func (s *Session) SetPackage(pkg *utils.ParsedPackage) {
+	same := pkg == s.pkg
+	if !same && s.pkg != nil {
+		s.pkg.Cleanup()
+	}
+	pkg.IsUpdate = false
+	pkg.CurrentIndex = nil
+	if s.manager != nil {
+		for _, entry := range s.manager.Index {
+			if entry.ID == pkg.ControllerID {
+				current := entry
+				pkg.IsUpdate = true
+				pkg.CurrentIndex = &current
+				break
+			}
+		}
+	}
+	s.pkg = pkg
+	if !same {
+		s.resetHistory()
+	}
+	s.audit()
+}
+
+// changed is called after the repository changed: published fingerprints are
+// recomputed on next use and the package under review is checked against
+// the new index and re-audited.
+func (s *Session) changed() {
+	s.prints, s.similar = nil, nil
+	if s.pkg != nil {
+		s.SetPackage(s.pkg)
+	}
+}
+
+// audit re-runs the rules on the current package against the open repository
+// and marks the findings the repository accepts for this controller.
+func (s *Session) audit() {
+	var repo *audit.Repo
+	if s.manager != nil {
+		repo = &audit.Repo{IDs: s.manager.IDs(), Similar: s.similarLayouts}
+	}
+	findings := audit.RunProfile(s.pkg, repo, s.profile)
+	findings = slices.DeleteFunc(findings, func(f audit.Finding) bool {
+		return slices.Contains(s.disabledRules, f.RuleID)
+	})
+	if s.pkg != nil {
+		findings = audit.Suppress(findings, s.pkg.ControllerID, s.suppressions(s.pkg.ControllerID))
+	}
+	s.findings = findings
+}
+
+// fingerprints returns the fingerprints of the published layouts, computing
+// them on first use.
+func (s *Session) fingerprints() map[string]audit.Fingerprint {
+	if s.prints == nil {
+		s.prints = s.manager.Fingerprints()
+	}
+	return s.prints
+}
+
+// similarLayouts compares f with the published layouts, reusing the last
+// result while f and the repository are unchanged.
+func (s *Session) similarLayouts(f audit.Fingerprint, self string) []audit.Match {
+	if s.similar == nil || s.similarID != self || !f.Equal(s.similarPrint) {
+		s.similar = audit.SimilarLayouts(f, s.fingerprints(), self)
+		s.similarPrint, s.similarID = f, self
+	}
+	return s.similar
+}
+
+// suppressions collects the accepted exceptions for controller id from the
+// repository rule config and, for published controllers, the controller's
+// ignore file. A broken ignore file suppresses nothing; Check Repository
+// reports it.
+func (s *Session) suppressions(id string) []audit.Suppression {
+	if s.manager == nil || id == "" {
+		return nil
+	}
+	list := s.profiles.SuppressionsFor(id)
+	if !slices.Contains(s.manager.IDs(), id) {
+		return list
+	}
+	if own, err := s.manager.Suppressions(id); err == nil {
+		list = append(list, own...)
+	}
+	return list
+}
+
+// Profiles lists the audit profiles defined by the open repository.
+func (s *Session) Profiles() []string {
+	if s.profiles == nil {
+		return []string{}
+	}
+	return s.profiles.Names()
+}
+
+// ProfileName returns the selected audit profile, or "" for the built-in rules.
+func (s *Session) ProfileName() string {
+	if s.profile == nil {
+		return ""
+	}
+	return s.profile.Name
+}
+
+// RequiredLocales returns the locales the selected profile requires every
+// controller to be named in, possibly none.
+func (s *Session) RequiredLocales() []string {
+	if locales := s.profile.Limits().RequiredLocales; locales != nil {
+		return locales
+	}
+	return []string{}
+}
+
+// SelectProfile switches to the named audit profile of the open repository;
+// an empty name selects the repository's default. The current package is
+// re-audited.
+func (s *Session) SelectProfile(name string) error {
+	if _, err := s.Repo(); err != nil {
+		return err
+	}
+	p, err := s.profiles.Profile(name)
+	if err != nil {
+		return err
+	}
+	s.profile = p
+	s.audit()
+	return nil
+}
+
+// SetDisabledRules turns off the given audit rules and re-audits the
+// current package.
+func (s *Session) SetDisabledRules(ids []string) {
+	s.disabledRules = slices.Clone(ids)
+	s.audit()
+}
+
+// current returns the package under review, or ErrNoPackage.
+func (s *Session) current() (*utils.ParsedPackage, error) {
+	if s.pkg == nil {
+		return nil, ErrNoPackage
+	}
+	return s.pkg, nil
+}
+
+// SetIcon replaces the icon of the package under review.
+func (s *Session) SetIcon(path string) error {
+	return s.edit(func(pkg *utils.ParsedPackage) error {
+		return pkg.SetIcon(path)
+	})
+}
+
+// AddScreenshots appends screenshots to the package under review.
+func (s *Session) AddScreenshots(paths ...string) error {
+	return s.edit(func(pkg *utils.ParsedPackage) error {
+		return pkg.AddScreenshots(paths...)
+	})
+}
+
+// ResolveConflict copies field from source into the package's other files.
+func (s *Session) ResolveConflict(field, source string) error {
+	return s.edit(func(pkg *utils.ParsedPackage) error {
+		return pkg.Resolve(field, source)
+	})
+}
+
+// ProposeFixes lists the changes the selected fixers would make to the
+// layout under review without making them.
+func (s *Session) ProposeFixes(opts audit.FixOptions) ([]audit.Change, error) {
+	pkg, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	_, changes, err := audit.Fix(pkg.Layout, opts)
+	return changes, err
+}
+
+// ApplyFixes runs the selected fixers on the layout under review as one
+// undoable step and returns the changes made.
+func (s *Session) ApplyFixes(opts audit.FixOptions) ([]audit.Change, error) {
+	pkg, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	fixed, changes, err := audit.Fix(pkg.Layout, opts)
+	if err != nil || len(changes) == 0 {
+		return changes, err
+	}
+	err = s.edit(func(pkg *utils.ParsedPackage) error {
+		pkg.Layout = fixed
+		return nil
+	})
+	return changes, err
+}
+
+// SimilarControllers compares the layout under review with every published
+// controller and returns up to limit matches, closest first. The controller's
+// own published versions are not compared; limit <= 0 returns every match.
+func (s *Session) SimilarControllers(limit int) ([]audit.Match, error) {
+	if _, err := s.Repo(); err != nil {
+		return nil, err
+	}
+	pkg, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	matches := s.similarLayouts(audit.NewFingerprint(pkg.Layout), pkg.ControllerID)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// Comparison renders the layout under review next to the published layout
+// of controller id, with the matched elements outlined.
+func (s *Session) Comparison(id string) (*image.NRGBA, audit.Match, error) {
+	mgr, err := s.Repo()
+	if err != nil {
+		return nil, audit.Match{}, err
+	}
+	pkg, err := s.current()
+	if err != nil {
+		return nil, audit.Match{}, err
+	}
+	published, err := mgr.LoadPackage(id)
+	if err != nil {
+		return nil, audit.Match{}, err
+	}
+	if published.Layout == nil {
+		return nil, audit.Match{}, fmt.Errorf("controller %s has no layout", id)
+	}
+	match := audit.Match{ID: id}
+	match.Score, match.Pairs = audit.NewFingerprint(pkg.Layout).Compare(audit.NewFingerprint(published.Layout))
+	w, h := models.PreviewWidth, models.PreviewHeight
+	if s.PreviewWidth > 0 && s.PreviewHeight > 0 {
+		w, h = s.PreviewWidth, s.PreviewHeight
+	}
+	return report.RenderComparison(pkg.Layout, published.Layout, w, h, match), match, nil
+}
+
+// Metadata is the reviewer-editable part of a package.
+type Metadata struct {
+	Categories  []int                   `json:"categories"`
+	Devices     []models.Device         `json:"devices"`
+	Author      string                  `json:"author"`
+	Description string                  `json:"description"`
+	Locales     []models.LocalizedEntry `json:"locales"`
+}
+
+// Metadata returns the current metadata of the package under review.
+func (s *Session) Metadata() (Metadata, error) {
+	pkg, err := s.current()
+	if err != nil {
+		return Metadata{}, err
+	}
+	var md Metadata
+	if pkg.IndexEntry != nil {
+		md.Categories = append([]int{}, pkg.IndexEntry.Categories...)
+		md.Devices = append([]models.Device{}, pkg.IndexEntry.Device...)
+		md.Locales = pkg.IndexEntry.Locales()
+	}
+	if pkg.VersionInfo != nil {
+		md.Author = pkg.VersionInfo.Author
+		md.Description = pkg.VersionInfo.Description
+	}
+	return md, nil
+}
+
+// EditMetadata validates md and writes it into the package under review as
+// one undoable step. An empty author or description leaves the existing
+// value in place.
+func (s *Session) EditMetadata(md Metadata) error {
+	if _, err := s.current(); err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, l := range md.Locales {
+		if strings.TrimSpace(l.Locale) == "" || strings.TrimSpace(l.Name) == "" {
+			return fmt.Errorf("every locale needs a locale code and a name")
+		}
+		if seen[l.Locale] {
+			return fmt.Errorf("locale %s given more than once", l.Locale)
+		}
+		seen[l.Locale] = true
+	}
+	for _, d := range md.Devices {
+		if !d.Valid() {
+			return fmt.Errorf("unknown device type %d", int(d))
+		}
+	}
+
+	return s.edit(func(pkg *utils.ParsedPackage) error {
+		if pkg.IndexEntry != nil {
+			pkg.IndexEntry.Categories = md.Categories
+			pkg.IndexEntry.Device = md.Devices
+			pkg.IndexEntry.SetLocales(md.Locales)
+		}
+		if pkg.VersionInfo != nil {
+			if md.Author != "" {
+				pkg.VersionInfo.Author = md.Author
+			}
+			if md.Description != "" {
+				pkg.VersionInfo.Description = md.Description
+			}
+		}
+		return nil
+	})
+}
+
+// Apply publishes the package under review to the repository.
+func (s *Session) Apply(reviewer, notes string) error {
+	mgr, err := s.Repo()
+	if err != nil {
+		return err
+	}
+	pkg, err := s.current()
+	if err != nil {
+		return err
+	}
+	s.audit()
+	err = mgr.ApplyUpdate(pkg, repository.Review{
+		Reviewer: reviewer,
+		Notes:    notes,
+		Findings: audit.Summarize(s.findings),
+	})
+	s.changed()
+	return err
+}
+
+// Reject writes the feedback documents for the package under review into dir
+// and, when a repository is open, records the rejection in its audit log.
+func (s *Session) Reject(dir, reviewer, notes string, langs ...string) ([]string, error) {
+	pkg, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	r := report.NewRejection(pkg, s.findings, reviewer, notes)
+	r.PreviewWidth, r.PreviewHeight = s.PreviewWidth, s.PreviewHeight
+	files, err := r.Write(dir, langs...)
+	if err != nil {
+		return nil, err
+	}
+	if s.manager != nil {
+		err := s.manager.RecordRejection(pkg, repository.Review{
+			Reviewer: reviewer,
+			Notes:    notes,
+			Findings: audit.Summarize(s.findings),
+		})
+		if err != nil {
+			return files, err
+		}
+	}
+	return files, nil
+}
+
+// Close removes the temporary files of the package under review.
+func (s *Session) Close() {
+	if s.pkg != nil {
+		s.pkg.Cleanup()
+	}
+}
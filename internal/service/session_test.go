@@ -0,0 +1,163 @@
+package service
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/audit"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/repository"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+var testReview = repository.Review{Reviewer: "tester"}
+
+// newTestSession opens a repository with an empty index in a session.
+func newTestSession(t *testing.T) *Session {
+	t.Helper()
+	root := t.TempDir()
+	for name, v := range map[string]any{
+		"index.json":    []models.IndexEntry{},
+		"category.json": []models.Category{{ID: 1, Lang: []models.LocalizedText{{Locale: "en", Text: "Action"}}}},
+	} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := New()
+	if _, err := s.OpenRepo(root); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+func testPackage(t *testing.T, id string) *utils.ParsedPackage {
+	t.Helper()
+	layout := &models.ControllerLayout{ID: id, Name: id, Version: "1.0", VersionCode: 1}
+	path := filepath.Join(t.TempDir(), "layout.json")
+	data, err := json.Marshal(layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := utils.NewPackageFromLayout(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg.TempDir = t.TempDir()
+	return pkg
+}
+
+func TestRepositoryChangesRefreshThePackage(t *testing.T) {
+	s := newTestSession(t)
+	pkg := testPackage(t, "demo")
+	s.SetPackage(pkg)
+	if pkg.IsUpdate {
+		t.Fatal("new controller marked as an update")
+	}
+
+	if err := s.Apply("tester", ""); err != nil {
+		t.Fatal(err)
+	}
+	if !pkg.IsUpdate || pkg.CurrentIndex == nil {
+		t.Error("package not marked as an update after it was applied")
+	}
+
+	if err := s.RemoveController("demo", false, testReview); err != nil {
+		t.Fatal(err)
+	}
+	if pkg.IsUpdate || pkg.CurrentIndex != nil {
+		t.Error("package still marked as an update after its controller was removed")
+	}
+}
+
+func TestCategoryChangesGoThroughSession(t *testing.T) {
+	s := newTestSession(t)
+	cat, err := s.CreateCategory(nil, []models.LocalizedText{{Locale: "en", Text: "Racing"}}, testReview)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.MergeCategories(cat.ID, 1, testReview); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(s.Manager().Categories); got != 1 {
+		t.Errorf("%d categories, want 1", got)
+	}
+	if err := s.DeleteCategory(1, nil, repository.Review{}); err == nil {
+		t.Error("deleting without a reviewer should fail")
+	}
+}
+
+func TestSetPackageRemovesPreviousTempDir(t *testing.T) {
+	s := New()
+	first, second := testPackage(t, "first"), testPackage(t, "second")
+	s.SetPackage(first)
+	s.SetPackage(first)
+	if _, err := os.Stat(first.TempDir); err != nil {
+		t.Fatalf("re-selecting the package removed its files: %v", err)
+	}
+	s.SetPackage(second)
+	if _, err := os.Stat(first.TempDir); !os.IsNotExist(err) {
+		t.Errorf("previous package's temp dir still exists: %v", err)
+	}
+	s.Close()
+	if _, err := os.Stat(second.TempDir); !os.IsNotExist(err) {
+		t.Errorf("Close left the temp dir behind: %v", err)
+	}
+}
+
+func TestSimilarLayoutsCachedPerFingerprint(t *testing.T) {
+	s := newTestSession(t)
+	pkg := testPackage(t, "demo")
+	pkg.Layout.ViewGroups = []models.ViewGroup{{
+		ID:         "g",
+		Visibility: models.VisibilityShown,
+		ViewData: models.ViewData{ButtonList: []models.Button{{
+			ID:       "b",
+			BaseInfo: models.BaseInfo{XPosition: 100, YPosition: 100, SizeType: models.SizeAbsolute, AbsoluteWidth: 60, AbsoluteHeight: 60},
+		}}},
+	}}
+	s.SetPackage(pkg)
+	// Swap the published fingerprints behind the cache's back, so only a
+	// fresh comparison can see them.
+	s.prints = map[string]audit.Fingerprint{"orig": audit.NewFingerprint(pkg.Layout)}
+	similar := func() int {
+		t.Helper()
+		matches, err := s.SimilarControllers(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(matches)
+	}
+
+	if err := s.edit(func(pkg *utils.ParsedPackage) error { pkg.Layout.Name = "renamed"; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if n := similar(); n != 0 {
+		t.Errorf("an edit outside the fingerprint recompared the layout: %d matches", n)
+	}
+
+	if err := s.edit(func(pkg *utils.ParsedPackage) error {
+		pkg.Layout.ViewGroups[0].ViewData.ButtonList[0].BaseInfo.XPosition = 110
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if n := similar(); n != 1 {
+		t.Errorf("moving a button kept the cached matches: %d matches", n)
+	}
+
+	s.changed()
+	if n := similar(); n != 0 {
+		t.Errorf("a repository change kept the cached matches: %d matches", n)
+	}
+}
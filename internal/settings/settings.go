@@ -0,0 +1,172 @@
+// Package settings persists reviewer preferences between launches in the
+// user config directory.
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+// MaxRecentRepos is how many repositories are remembered.
+const MaxRecentRepos = 10
+
+// Resolution is a width and height in pixels.
+type Resolution struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Audit configures which audit rules run. Profile names a profile from the
+// repository's audit-rules.json; empty uses the repository default.
+type Audit struct {
+	Profile       string   `json:"profile,omitempty"`
+	DisabledRules []string `json:"disabledRules,omitempty"`
+}
+
+// Settings is everything remembered between launches.
+type Settings struct {
+	RecentRepos []string   `json:"recentRepos"`
+	Reviewer    string     `json:"reviewer"`
+	InboxDir    string     `json:"inboxDir"`
+	Audit       Audit      `json:"audit"`
+	Preview     Resolution `json:"preview"`
+}
+
+// Default returns the settings used before anything has been saved.
+func Default() Settings {
+	return Settings{
+		RecentRepos: []string{},
+		Preview:     Resolution{Width: models.PreviewWidth, Height: models.PreviewHeight},
+	}
+}
+
+// LastRepo returns the most recently opened repository, or "".
+func (s Settings) LastRepo() string {
+	if len(s.RecentRepos) == 0 {
+		return ""
+	}
+	return s.RecentRepos[0]
+}
+
+func (s Settings) clone() Settings {
+	s.RecentRepos = slices.Clone(s.RecentRepos)
+	s.Audit.DisabledRules = slices.Clone(s.Audit.DisabledRules)
+	return s
+}
+
+// normalize fills in defaults and drops duplicate or empty entries.
+func (s *Settings) normalize() {
+	var repos []string
+	for _, r := range s.RecentRepos {
+		if r != "" && !slices.Contains(repos, r) {
+			repos = append(repos, r)
+		}
+	}
+	if len(repos) > MaxRecentRepos {
+		repos = repos[:MaxRecentRepos]
+	}
+	if repos == nil {
+		repos = []string{}
+	}
+	s.RecentRepos = repos
+	if s.Preview.Width <= 0 || s.Preview.Height <= 0 {
+		s.Preview = Resolution{Width: models.PreviewWidth, Height: models.PreviewHeight}
+	}
+}
+
+// DefaultPath returns the settings file inside the user config dir.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "fcl-controller-auditor", "settings.json"), nil
+}
+
+// Store reads and writes the settings file at Path.
+type Store struct {
+	Path string
+
+	mu      sync.Mutex
+	current Settings
+}
+
+// Open loads the settings at path. A missing file yields the defaults; an
+// unreadable one yields the defaults together with the error, and is only
+// overwritten by the next Update.
+func Open(path string) (*Store, error) {
+	s := &Store{Path: path, current: Default()}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	var st Settings
+	if err := json.Unmarshal(data, &st); err != nil {
+		return s, err
+	}
+	st.normalize()
+	s.current = st
+	return s, nil
+}
+
+// Get returns a copy of the current settings.
+func (s *Store) Get() Settings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current.clone()
+}
+
+// Update applies fn to the settings and saves them.
+func (s *Store) Update(fn func(*Settings)) (Settings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := s.current.clone()
+	fn(&next)
+	next.normalize()
+	if err := s.save(next); err != nil {
+		return s.current.clone(), err
+	}
+	s.current = next
+	return next.clone(), nil
+}
+
+// AddRecentRepo moves root to the front of the recent repositories.
+func (s *Store) AddRecentRepo(root string) error {
+	_, err := s.Update(func(st *Settings) {
+		st.RecentRepos = append([]string{root}, st.RecentRepos...)
+	})
+	return err
+}
+
+// RemoveRecentRepo forgets root, e.g. after it could not be opened.
+func (s *Store) RemoveRecentRepo(root string) error {
+	_, err := s.Update(func(st *Settings) {
+		st.RecentRepos = slices.DeleteFunc(st.RecentRepos, func(r string) bool { return r == root })
+	})
+	return err
+}
+
+// save writes st through a temporary file so a crash cannot truncate it.
+func (s *Store) save(st Settings) error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
@@ -0,0 +1,144 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+func TestNormalize(t *testing.T) {
+	var many []string
+	for i := range MaxRecentRepos + 3 {
+		many = append(many, fmt.Sprintf("/repo%d", i))
+	}
+	tests := []struct {
+		name      string
+		in        Settings
+		wantRepos []string
+		wantSize  Resolution
+	}{
+		{"zero value", Settings{}, []string{}, Resolution{models.PreviewWidth, models.PreviewHeight}},
+		{"duplicates and empties", Settings{
+			RecentRepos: []string{"/b", "", "/a", "/b", "/a"},
+			Preview:     Resolution{800, 450},
+		}, []string{"/b", "/a"}, Resolution{800, 450}},
+		{"capped", Settings{RecentRepos: many, Preview: Resolution{800, 450}}, many[:MaxRecentRepos], Resolution{800, 450}},
+		{"half a resolution", Settings{Preview: Resolution{Width: 800}}, []string{}, Resolution{models.PreviewWidth, models.PreviewHeight}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := tt.in
+			st.normalize()
+			if !reflect.DeepEqual(st.RecentRepos, tt.wantRepos) {
+				t.Errorf("RecentRepos = %q, want %q", st.RecentRepos, tt.wantRepos)
+			}
+			if st.Preview != tt.wantSize {
+				t.Errorf("Preview = %+v, want %+v", st.Preview, tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestRecentRepos(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "settings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, root := range []string{"/a", "/b", "/a", "/c"} {
+		if err := s.AddRecentRepo(root); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := s.Get().RecentRepos, []string{"/c", "/a", "/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RecentRepos = %q, want %q", got, want)
+	}
+	if err := s.RemoveRecentRepo("/c"); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Get().LastRepo(); got != "/a" {
+		t.Errorf("LastRepo() = %q, want /a", got)
+	}
+	for i := range MaxRecentRepos + 2 {
+		if err := s.AddRecentRepo(fmt.Sprintf("/repo%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := s.Get().RecentRepos; len(got) != MaxRecentRepos {
+		t.Errorf("remembered %d repositories, want %d", len(got), MaxRecentRepos)
+	}
+}
+
+func TestUpdatePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "settings.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := s.Update(func(st *Settings) {
+		st.Reviewer = "alice"
+		st.Audit.DisabledRules = []string{"layout.coverage"}
+		st.Preview = Resolution{}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Preview.Width != models.PreviewWidth {
+		t.Errorf("Update returned an unnormalized preview %+v", saved.Preview)
+	}
+	// The returned copy does not alias the store.
+	saved.Audit.DisabledRules[0] = "changed"
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.Get(); got.Reviewer != "alice" || !reflect.DeepEqual(got.Audit.DisabledRules, []string{"layout.coverage"}) {
+		t.Errorf("reopened settings = %+v", got)
+	}
+	if got := s.Get().Audit.DisabledRules[0]; got != "layout.coverage" {
+		t.Errorf("store settings were changed through a returned copy: %q", got)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
+
+func TestUpdateKeepsSettingsOnSaveError(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(filepath.Join(dir, "settings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Update(func(st *Settings) { st.Reviewer = "alice" }); err != nil {
+		t.Fatal(err)
+	}
+	// A directory in the way of the temporary file makes the save fail.
+	if err := os.Mkdir(s.Path+".tmp", 0755); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Update(func(st *Settings) { st.Reviewer = "bob" })
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if got.Reviewer != "alice" || s.Get().Reviewer != "alice" {
+		t.Errorf("reviewer = %q / %q after a failed save, want alice", got.Reviewer, s.Get().Reviewer)
+	}
+}
+
+func TestOpenUnreadableFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(path)
+	if err == nil {
+		t.Error("expected an error")
+	}
+	if !reflect.DeepEqual(s.Get(), Default()) {
+		t.Errorf("settings = %+v, want the defaults", s.Get())
+	}
+}
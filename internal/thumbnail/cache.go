@@ -0,0 +1,179 @@
+// Package thumbnail keeps resized copies of package images on disk so the
+// UI does not have to load full-size icons and screenshots.
+package thumbnail
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/imaging"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
+)
+
+// Route is the URL path the cache is served under.
+const Route = "/thumbnails/"
+
+// DefaultSize is the longest side of a thumbnail when no size is requested.
+const DefaultSize = 256
+
+const maxSize = 2048
+
+// source remembers the content hash of a file until it changes on disk.
+type source struct {
+	modTime time.Time
+	size    int64
+	hash    string
+}
+
+// Cache generates thumbnails into Dir. Thumbnails are keyed by the SHA-256
+// of the source file, so an edited file gets a new thumbnail and the old
+// ones are removed.
+type Cache struct {
+	Dir string
+
+	// Roots returns the directories images may be served from. Requests for
+	// any other path are answered with 404; a nil Roots serves nothing.
+	Roots func() []string
+
+	mu      sync.Mutex
+	sources map[string]source
+}
+
+// New returns a cache storing thumbnails in dir.
+func New(dir string) *Cache {
+	return &Cache{Dir: dir, sources: make(map[string]source)}
+}
+
+// DefaultDir returns the thumbnail directory inside the user cache dir.
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "fcl-controller-auditor", "thumbnails"), nil
+}
+
+// Thumbnail returns the path of a PNG thumbnail of src whose longest side is
+// at most size pixels, generating it if needed, and the source's hash.
+func (c *Cache) Thumbnail(src string, size int) (path, hash string, err error) {
+	hash, err = c.hash(src)
+	if err != nil {
+		return "", "", err
+	}
+	path = filepath.Join(c.Dir, fmt.Sprintf("%s-%d.png", hash, size))
+	if _, err := os.Stat(path); err == nil {
+		return path, hash, nil
+	}
+
+	_, img, err := imaging.Inspect(src)
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return "", "", err
+	}
+	tmp := fmt.Sprintf("%s.%d.tmp", path, time.Now().UnixNano())
+	if err := imaging.WritePNG(tmp, imaging.Downscale(img, size, size)); err != nil {
+		os.Remove(tmp)
+		return "", "", err
+	}
+	return path, hash, os.Rename(tmp, path)
+}
+
+// hash returns the content hash of src, rehashing only when its size or
+// modification time changed.
+func (c *Cache) hash(src string) (string, error) {
+	fi, err := os.Stat(src)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	s, ok := c.sources[src]
+	c.mu.Unlock()
+	if ok && s.modTime.Equal(fi.ModTime()) && s.size == fi.Size() {
+		return s.hash, nil
+	}
+
+	hash, err := utils.FileSHA256(src)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.sources[src] = source{modTime: fi.ModTime(), size: fi.Size(), hash: hash}
+	c.mu.Unlock()
+	if ok && s.hash != hash {
+		c.removeThumbnails(s.hash)
+	}
+	return hash, nil
+}
+
+func (c *Cache) removeThumbnails(hash string) {
+	matches, _ := filepath.Glob(filepath.Join(c.Dir, hash+"-*.png"))
+	for _, m := range matches {
+		os.Remove(m)
+	}
+}
+
+// allowed reports whether src lies inside one of the Roots.
+func (c *Cache) allowed(src string) bool {
+	if c.Roots == nil || !filepath.IsAbs(src) {
+		return false
+	}
+	for _, root := range c.Roots() {
+		if root == "" {
+			continue
+		}
+		root, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, src)
+		if err == nil && filepath.IsLocal(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// ServeHTTP serves Route?path=<image>&size=<pixels> for images inside the
+// Roots. Responses carry the source hash as ETag so the webview revalidates
+// instead of refetching.
+func (c *Cache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	src := r.URL.Query().Get("path")
+	if src == "" || !utils.IsScreenshotFile(src) {
+		http.NotFound(w, r)
+		return
+	}
+	src = filepath.Clean(src)
+	if !c.allowed(src) {
+		http.NotFound(w, r)
+		return
+	}
+	size := DefaultSize
+	if s := r.URL.Query().Get("size"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || n > maxSize {
+			http.Error(w, "invalid size", http.StatusBadRequest)
+			return
+		}
+		size = n
+	}
+
+	path, hash, err := c.Thumbnail(src, size)
+	if os.IsNotExist(err) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", `"`+hash+`"`)
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeFile(w, r, path)
+}
@@ -0,0 +1,62 @@
+package thumbnail
+
+import (
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/imaging"
+)
+
+func TestServeHTTPOnlyServesRoots(t *testing.T) {
+	repo, pkg, other := t.TempDir(), t.TempDir(), t.TempDir()
+	for _, dir := range []string{repo, pkg, other} {
+		if err := imaging.WritePNG(filepath.Join(dir, "1.png"), image.NewNRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(repo, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	c := New(t.TempDir())
+	c.Roots = func() []string { return []string{repo, "", pkg} }
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"repository image", filepath.Join(repo, "1.png"), http.StatusOK},
+		{"package image", filepath.Join(pkg, "1.png"), http.StatusOK},
+		{"outside the roots", filepath.Join(other, "1.png"), http.StatusNotFound},
+		{"escaping with ..", filepath.Join(repo, "sub") + "/../../" + filepath.Base(other) + "/1.png", http.StatusNotFound},
+		{"relative path", "1.png", http.StatusNotFound},
+		{"not an image", filepath.Join(repo, "index.json"), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Route+"?path="+url.QueryEscape(tt.path), nil))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeHTTPWithoutRoots(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "1.png")
+	if err := imaging.WritePNG(path, image.NewNRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	New(t.TempDir()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Route+"?path="+url.QueryEscape(path), nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
@@ -2,6 +2,10 @@ package ui
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -10,30 +14,34 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
-	"github.com/tungsten-fcl/fcl-controller-auditor/internal/repository"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/audit"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/report"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/service"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/settings"
 	"github.com/tungsten-fcl/fcl-controller-auditor/internal/utils"
 )
 
 type AuditorApp struct {
-	App        fyne.App
-	Window     fyne.Window
-	RepoMgr    *repository.Manager
-	CurrentPkg *utils.ParsedPackage
+	App      fyne.App
+	Window   fyne.Window
+	Session  *service.Session
+	Settings *settings.Store
 
 	// UI Components
 	ControllerList *widget.List
+	RepoLabel      *widget.Label
+	ProfileSelect  *widget.Select
 	InfoLabel      *widget.Label
+	FindingsLabel  *widget.Label
 	IconImage      *canvas.Image
 	Preview        *ControllerPreview
 	ScreenshotCont *fyne.Container
 }
 
+// NewAuditorApp creates the Fyne front end. repoRoot may be empty, in which
+// case the last opened repository is reopened, if any.
 func NewAuditorApp(repoRoot string) (*AuditorApp, error) {
-	mgr, err := repository.NewManager(repoRoot)
-	if err != nil {
-		return nil, err
-	}
-
 	a := app.New()
 	w := a.NewWindow("FCL Controller Auditor")
 	w.Resize(fyne.NewSize(1200, 800))
@@ -41,28 +49,62 @@ func NewAuditorApp(repoRoot string) (*AuditorApp, error) {
 	auditor := &AuditorApp{
 		App:     a,
 		Window:  w,
-		RepoMgr: mgr,
+		Session: service.New(),
 	}
-
 	auditor.setupUI()
+
+	path, err := settings.DefaultPath()
+	if err != nil {
+		path = filepath.Join(os.TempDir(), "fcl-controller-auditor-settings.json")
+	}
+	if auditor.Settings, err = settings.Open(path); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+	st := auditor.Settings.Get()
+	auditor.Session.PreviewWidth = st.Preview.Width
+	auditor.Session.PreviewHeight = st.Preview.Height
+	auditor.Session.SetDisabledRules(st.Audit.DisabledRules)
+
+	if repoRoot != "" {
+		if err := auditor.openRepo(repoRoot); err != nil {
+			return nil, err
+		}
+	} else if last := st.LastRepo(); last != "" {
+		if err := auditor.openRepo(last); err != nil {
+			auditor.Settings.RemoveRecentRepo(last)
+		}
+	}
 	return auditor, nil
 }
 
 func (a *AuditorApp) setupUI() {
 	// Left side: Controller List
 	a.ControllerList = widget.NewList(
-		func() int { return len(a.RepoMgr.Index) },
+		func() int {
+			if a.Session.Manager() == nil {
+				return 0
+			}
+			return len(a.Session.Manager().Index)
+		},
 		func() fyne.CanvasObject { return widget.NewLabel("Template") },
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
-			obj.(*widget.Label).SetText(a.RepoMgr.Index[id].Name)
+			entry := a.Session.Manager().Index[id]
+			name := entry.Name
+			if entry.Deprecated != nil {
+				name += " (deprecated)"
+			}
+			obj.(*widget.Label).SetText(name)
 		},
 	)
 	a.ControllerList.OnSelected = func(id widget.ListItemID) {
-		a.loadController(a.RepoMgr.Index[id].ID)
+		a.loadController(a.Session.Manager().Index[id].ID)
 	}
 
 	// Right side: Details & Preview
+	a.RepoLabel = widget.NewLabel("No repository selected")
 	a.InfoLabel = widget.NewLabel("Select a controller to view details")
+	a.FindingsLabel = widget.NewLabel("")
+	a.FindingsLabel.Wrapping = fyne.TextWrapWord
 	a.IconImage = canvas.NewImageFromResource(nil)
 	a.IconImage.FillMode = canvas.ImageFillContain
 	a.IconImage.SetMinSize(fyne.NewSize(64, 64))
@@ -70,10 +112,26 @@ func (a *AuditorApp) setupUI() {
 	a.Preview = NewControllerPreview(nil)
 	a.ScreenshotCont = container.NewHBox()
 
+	a.ProfileSelect = widget.NewSelect(nil, a.selectProfile)
+	a.ProfileSelect.PlaceHolder = "Built-in rules"
+
 	// Toolbar
-	loadZipBtn := widget.NewButton("Load ZIP Package", a.showZipPicker)
-	applyBtn := widget.NewButton("Apply Update", a.applyUpdate)
-	toolbar := container.NewHBox(loadZipBtn, applyBtn)
+	toolbar := container.NewHBox(
+		widget.NewButton("Open Repository", a.showRepoPicker),
+		widget.NewButton("Load ZIP Package", a.showZipPicker),
+		widget.NewButton("New From Layout", a.showLayoutPicker),
+		widget.NewButton("Edit Metadata", a.editMetadata),
+		widget.NewButton("Undo", func() { a.history(a.Session.Undo) }),
+		widget.NewButton("Redo", func() { a.history(a.Session.Redo) }),
+		widget.NewButton("Reset Edits", func() { a.history(a.Session.ResetToSubmitted) }),
+		widget.NewButton("Auto Fix", a.autoFix),
+		widget.NewButton("Similar", a.showSimilar),
+		widget.NewButton("Apply Update", a.applyUpdate),
+		widget.NewButton("Reject", a.rejectPackage),
+		widget.NewButton("Check Repository", a.checkRepository),
+		widget.NewButton("Analytics", a.writeAnalytics),
+		a.ProfileSelect,
+	)
 
 	// Right side details structure
 	infoSection := container.NewVBox(
@@ -82,6 +140,11 @@ func (a *AuditorApp) setupUI() {
 		widget.NewLabelWithStyle("Preview", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 	)
 
+	findingsSection := container.NewVBox(
+		widget.NewLabelWithStyle("Findings", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		a.FindingsLabel,
+	)
+
 	screenshotSection := container.NewVBox(
 		widget.NewLabelWithStyle("Screenshots", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		container.NewHScroll(a.ScreenshotCont),
@@ -89,26 +152,71 @@ func (a *AuditorApp) setupUI() {
 
 	details := container.NewBorder(
 		infoSection,
-		screenshotSection,
+		container.NewVBox(findingsSection, screenshotSection),
 		nil,
 		nil,
-		container.NewMax(a.Preview),
+		container.NewStack(a.Preview),
 	)
 
 	split := container.NewHSplit(
-		container.NewBorder(widget.NewLabelWithStyle("Controllers", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}), nil, nil, nil, a.ControllerList),
-		container.NewBorder(toolbar, nil, nil, nil, details),
+		container.NewBorder(widget.NewLabelWithStyle("Controllers", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}), a.RepoLabel, nil, nil, a.ControllerList),
+		container.NewBorder(toolbar, nil, nil, nil, container.NewVScroll(details)),
 	)
 	split.Offset = 0.2
 
 	a.Window.SetContent(split)
 }
 
+func (a *AuditorApp) openRepo(root string) error {
+	if _, err := a.Session.OpenRepo(root); err != nil {
+		return err
+	}
+	a.RepoLabel.SetText(root)
+	if name := a.Settings.Get().Audit.Profile; name != "" {
+		// The saved profile may not exist in this repository
+		a.Session.SelectProfile(name)
+	}
+	a.ProfileSelect.Options = a.Session.Profiles()
+	a.ProfileSelect.SetSelected(a.Session.ProfileName())
+	a.ControllerList.UnselectAll()
+	a.ControllerList.Refresh()
+	if err := a.Settings.AddRecentRepo(root); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+	return nil
+}
+
+// selectProfile switches the audit profile and remembers the choice.
+func (a *AuditorApp) selectProfile(name string) {
+	if name == a.Session.ProfileName() {
+		return
+	}
+	if err := a.Session.SelectProfile(name); err != nil {
+		dialog.ShowError(err, a.Window)
+		return
+	}
+	a.Settings.Update(func(st *settings.Settings) { st.Audit.Profile = name })
+	a.showFindings()
+}
+
+func (a *AuditorApp) showRepoPicker() {
+	dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+		if err != nil || uri == nil {
+			return
+		}
+		if err := a.openRepo(uri.Path()); err != nil {
+			dialog.ShowError(err, a.Window)
+		}
+	}, a.Window)
+}
+
 func (a *AuditorApp) loadController(id string) {
-	// Implementation for loading existing controller for comparison...
-	// For now, just clear the current package
-	a.CurrentPkg = nil
-	a.InfoLabel.SetText(fmt.Sprintf("Loading ID: %s", id))
+	pkg, err := a.Session.LoadController(id)
+	if err != nil {
+		dialog.ShowError(err, a.Window)
+		return
+	}
+	a.displayPackage(pkg)
 }
 
 func (a *AuditorApp) showZipPicker() {
@@ -118,34 +226,99 @@ func (a *AuditorApp) showZipPicker() {
 		}
 		defer reader.Close()
 
-		pkg, err := utils.ParseControllerZip(reader.URI().Path())
+		pkg, err := a.Session.OpenZip(reader.URI().Path())
 		if err != nil {
 			dialog.ShowError(err, a.Window)
 			return
 		}
-
 		a.displayPackage(pkg)
 	}, a.Window)
 	fd.SetFilter(storage.NewExtensionFileFilter([]string{".zip"}))
+	if inbox := a.Settings.Get().InboxDir; inbox != "" {
+		if uri, err := storage.ListerForURI(storage.NewFileURI(inbox)); err == nil {
+			fd.SetLocation(uri)
+		}
+	}
+	fd.Show()
+}
+
+func (a *AuditorApp) showLayoutPicker() {
+	fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil || reader == nil {
+			return
+		}
+		defer reader.Close()
+
+		pkg, err := a.Session.OpenLayout(reader.URI().Path())
+		if err != nil {
+			dialog.ShowError(err, a.Window)
+			return
+		}
+		a.displayPackage(pkg)
+	}, a.Window)
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
 	fd.Show()
 }
 
+// showFindings lists the session's audit findings for the current package.
+func (a *AuditorApp) showFindings() {
+	findings := a.Session.Findings()
+	if len(findings) == 0 {
+		a.FindingsLabel.SetText("No problems found")
+		return
+	}
+	lines := make([]string, len(findings))
+	for i, f := range findings {
+		lines[i] = f.String()
+	}
+	a.FindingsLabel.SetText(strings.Join(lines, "\n"))
+}
+
 func (a *AuditorApp) displayPackage(pkg *utils.ParsedPackage) {
-	a.CurrentPkg = pkg
-	a.InfoLabel.SetText(fmt.Sprintf(
-		"ID: %s\nName: %s\nAuthor: %s\nVersion: %s (%d)\nDescription: %s",
-		pkg.ControllerID, pkg.IndexEntry.Name, pkg.Layout.Author,
-		pkg.Layout.Version, pkg.VersionCode, pkg.Layout.Description,
-	))
 
+	name, author, version, description := "", "", "", ""
+	if pkg.IndexEntry != nil {
+		name = pkg.IndexEntry.Name
+	}
+	if pkg.VersionInfo != nil {
+		author = pkg.VersionInfo.Author
+		version = pkg.VersionInfo.Latest.VersionName
+		description = pkg.VersionInfo.Description
+	}
 	if pkg.Layout != nil {
-		a.Preview.SetLayout(pkg.Layout)
+		if name == "" {
+			name = pkg.Layout.Name
+		}
+		if author == "" {
+			author = pkg.Layout.Author
+		}
+		if version == "" {
+			version = pkg.Layout.Version
+		}
+		if description == "" {
+			description = pkg.Layout.Description
+		}
 	}
-
-	if pkg.IconPath != "" {
-		a.IconImage.File = pkg.IconPath
-		a.IconImage.Refresh()
+	status := "New"
+	if pkg.IsUpdate {
+		status = "Update"
+	}
+	m := audit.ComputeMetrics(pkg.Layout)
+	smallest := "-"
+	if len(m.SmallestTargets) > 0 {
+		smallest = fmt.Sprintf("%.1f mm (%s)", m.SmallestTargets[0].Millimetres, m.SmallestTargets[0].ElementID)
 	}
+	a.InfoLabel.SetText(fmt.Sprintf(
+		"ID: %s (%s)\nName: %s\nAuthor: %s\nVersion: %s (%d)\nDescription: %s\n"+
+			"Layout: %d groups, %d buttons, %d directions, %d keycodes, %.1f%% coverage, toggle depth %d, smallest %s",
+		pkg.ControllerID, status, name, author, version, pkg.VersionCode, description,
+		m.Groups, m.Buttons, m.Directions, m.Keycodes, m.Coverage, m.ToggleDepth, smallest,
+	))
+
+	a.Preview.SetLayout(pkg.Layout)
+
+	a.IconImage.File = pkg.IconPath
+	a.IconImage.Refresh()
 
 	// Update screenshots
 	a.ScreenshotCont.Objects = []fyne.CanvasObject{}
@@ -156,22 +329,343 @@ func (a *AuditorApp) displayPackage(pkg *utils.ParsedPackage) {
 		a.ScreenshotCont.Add(img)
 	}
 	a.ScreenshotCont.Refresh()
+
+	a.showFindings()
 }
 
-func (a *AuditorApp) applyUpdate() {
-	if a.CurrentPkg == nil {
+// editMetadata lets the reviewer change the localized names, author,
+// description, categories and devices of the current package.
+func (a *AuditorApp) editMetadata() {
+	pkg := a.Session.Package()
+	if pkg == nil || pkg.IndexEntry == nil {
+		dialog.ShowInformation("No Package", "Please load a package with an index.json first", a.Window)
+		return
+	}
+	md, err := a.Session.Metadata()
+	if err != nil {
+		dialog.ShowError(err, a.Window)
+		return
+	}
+
+	locales := md.Locales
+	for _, required := range a.Session.RequiredLocales() {
+		if _, ok := pkg.IndexEntry.Localized(required); !ok {
+			locales = append(locales, models.LocalizedEntry{Locale: required})
+		}
+	}
+	type localeEntries struct {
+		locale      string
+		name, intro *widget.Entry
+	}
+	var entries []localeEntries
+	form := widget.NewForm()
+	for _, l := range locales {
+		e := localeEntries{locale: l.Locale, name: widget.NewEntry(), intro: widget.NewEntry()}
+		e.name.SetText(l.Name)
+		e.intro.SetText(l.Introduction)
+		form.Append("Name ("+l.Locale+")", e.name)
+		form.Append("Introduction ("+l.Locale+")", e.intro)
+		entries = append(entries, e)
+	}
+
+	author := widget.NewEntry()
+	description := widget.NewMultiLineEntry()
+	author.SetText(md.Author)
+	description.SetText(md.Description)
+	form.Append("Author", author)
+	form.Append("Description", description)
+
+	var categoryNames []string
+	categoryIDs := make(map[string]int)
+	var selectedCategories []string
+	if mgr := a.Session.Manager(); mgr != nil {
+		for _, c := range mgr.Categories {
+			label := fmt.Sprintf("%d %s", c.ID, c.Text("en"))
+			categoryNames = append(categoryNames, label)
+			categoryIDs[label] = c.ID
+			for _, id := range md.Categories {
+				if id == c.ID {
+					selectedCategories = append(selectedCategories, label)
+				}
+			}
+		}
+	}
+	categories := widget.NewCheckGroup(categoryNames, nil)
+	categories.SetSelected(selectedCategories)
+	form.Append("Categories", categories)
+
+	var deviceNames, selectedDevices []string
+	var deviceValues []models.Device
+	for _, s := range audit.SuggestDevices(pkg.Layout) {
+		label := s.Name
+		if !s.Suitable {
+			label += " (not recommended)"
+		}
+		deviceNames = append(deviceNames, label)
+		deviceValues = append(deviceValues, s.Device)
+		for _, d := range md.Devices {
+			if d == s.Device {
+				selectedDevices = append(selectedDevices, label)
+			}
+		}
+	}
+	devices := widget.NewCheckGroup(deviceNames, nil)
+	devices.SetSelected(selectedDevices)
+	form.Append("Devices", devices)
+
+	content := container.NewVScroll(form)
+	content.SetMinSize(fyne.NewSize(600, 500))
+	dialog.ShowCustomConfirm("Edit Metadata", "Save", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		edited := service.Metadata{
+			Categories:  []int{},
+			Devices:     []models.Device{},
+			Author:      author.Text,
+			Description: description.Text,
+		}
+		for _, e := range entries {
+			if strings.TrimSpace(e.name.Text) == "" {
+				continue
+			}
+			edited.Locales = append(edited.Locales, models.LocalizedEntry{Locale: e.locale, Name: e.name.Text, Introduction: e.intro.Text})
+		}
+		for _, label := range categories.Selected {
+			edited.Categories = append(edited.Categories, categoryIDs[label])
+		}
+		for i, label := range deviceNames {
+			for _, sel := range devices.Selected {
+				if sel == label {
+					edited.Devices = append(edited.Devices, deviceValues[i])
+				}
+			}
+		}
+
+		if err := a.Session.EditMetadata(edited); err != nil {
+			dialog.ShowError(err, a.Window)
+			return
+		}
+		a.displayPackage(pkg)
+	}, a.Window)
+}
+
+// history runs an undo, redo or reset on the session and redisplays the package.
+func (a *AuditorApp) history(step func() error) {
+	if err := step(); err != nil {
+		dialog.ShowError(err, a.Window)
+		return
+	}
+	a.displayPackage(a.Session.Package())
+}
+
+// autoFix lists the changes the chosen layout fixers would make and applies
+// them as one undoable edit once the reviewer accepts.
+func (a *AuditorApp) autoFix() {
+	if a.Session.Package() == nil {
 		dialog.ShowInformation("No Package", "Please load a ZIP package first", a.Window)
 		return
 	}
 
-	err := a.RepoMgr.ApplyUpdate(a.CurrentPkg)
+	changesLabel := widget.NewLabel("")
+	fixers := widget.NewCheckGroup(audit.Fixers, nil)
+	propose := func(selected []string) {
+		if len(selected) == 0 {
+			changesLabel.SetText("No fixers selected")
+			return
+		}
+		changes, err := a.Session.ProposeFixes(audit.FixOptions{Fixers: selected})
+		switch {
+		case err != nil:
+			changesLabel.SetText(err.Error())
+		case len(changes) == 0:
+			changesLabel.SetText("Nothing to fix")
+		default:
+			lines := make([]string, len(changes))
+			for i, c := range changes {
+				lines[i] = c.String()
+			}
+			changesLabel.SetText(strings.Join(lines, "\n"))
+		}
+	}
+	fixers.OnChanged = propose
+	fixers.SetSelected(append([]string(nil), audit.Fixers...))
+
+	content := container.NewBorder(fixers, nil, nil, nil, container.NewVScroll(changesLabel))
+	d := dialog.NewCustomConfirm("Auto Fix", "Apply", "Cancel", content, func(ok bool) {
+		if !ok || len(fixers.Selected) == 0 {
+			return
+		}
+		a.history(func() error {
+			_, err := a.Session.ApplyFixes(audit.FixOptions{Fixers: fixers.Selected})
+			return err
+		})
+	}, a.Window)
+	d.Resize(fyne.NewSize(640, 480))
+	d.Show()
+}
+
+// showSimilar lists the published controllers whose layouts resemble the
+// package under review and shows the chosen one side by side with it.
+func (a *AuditorApp) showSimilar() {
+	if a.Session.Package() == nil {
+		dialog.ShowInformation("No Package", "Please load a ZIP package first", a.Window)
+		return
+	}
+	if a.Session.Manager() == nil {
+		dialog.ShowInformation("No Repository", "Please open a repository first", a.Window)
+		return
+	}
+	matches, err := a.Session.SimilarControllers(5)
 	if err != nil {
 		dialog.ShowError(err, a.Window)
 		return
 	}
+	if len(matches) == 0 {
+		dialog.ShowInformation("Similar Controllers", "No published controller has a similar layout", a.Window)
+		return
+	}
 
-	dialog.ShowInformation("Success", "Controller update applied successfully", a.Window)
-	a.ControllerList.Refresh()
+	image := canvas.NewImageFromResource(nil)
+	image.FillMode = canvas.ImageFillContain
+	image.SetMinSize(fyne.NewSize(960, 270))
+	pairsLabel := widget.NewLabel("")
+	options := make([]string, len(matches))
+	for i, m := range matches {
+		options[i] = fmt.Sprintf("%.0f%%  %s", m.Score, m.ID)
+	}
+	pick := widget.NewSelect(options, func(selected string) {
+		for i, option := range options {
+			if option != selected {
+				continue
+			}
+			img, match, err := a.Session.Comparison(matches[i].ID)
+			if err != nil {
+				pairsLabel.SetText(err.Error())
+				return
+			}
+			image.Image = img
+			image.Refresh()
+			lines := make([]string, len(match.Pairs))
+			for j, p := range match.Pairs {
+				lines[j] = fmt.Sprintf("%s -> %s  %.0f%%", p.Submitted, p.Published, p.Score)
+			}
+			pairsLabel.SetText(strings.Join(lines, "\n"))
+		}
+	})
+	pick.SetSelectedIndex(0)
+
+	content := container.NewBorder(container.NewVBox(pick, image), nil, nil, nil, container.NewVScroll(pairsLabel))
+	d := dialog.NewCustom("Similar Controllers", "Close", content, a.Window)
+	d.Resize(fyne.NewSize(1000, 640))
+	d.Show()
+}
+
+func (a *AuditorApp) applyUpdate() {
+	if a.Session.Package() == nil {
+		dialog.ShowInformation("No Package", "Please load a ZIP package first", a.Window)
+		return
+	}
+	if a.Session.Manager() == nil {
+		dialog.ShowInformation("No Repository", "Please open a repository first", a.Window)
+		return
+	}
+
+	reviewer := widget.NewEntry()
+	reviewer.SetText(a.Settings.Get().Reviewer)
+	notes := widget.NewMultiLineEntry()
+	items := []*widget.FormItem{
+		widget.NewFormItem("Reviewer", reviewer),
+		widget.NewFormItem("Notes", notes),
+	}
+	dialog.ShowForm("Apply Update", "Apply", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := a.Session.Apply(reviewer.Text, notes.Text); err != nil {
+			dialog.ShowError(err, a.Window)
+			return
+		}
+
+		dialog.ShowInformation("Success", "Controller update applied successfully", a.Window)
+		a.ControllerList.Refresh()
+	}, a.Window)
+}
+
+// rejectPackage writes the feedback documents into a chosen folder and
+// records the rejection in the audit log.
+func (a *AuditorApp) rejectPackage() {
+	if a.Session.Package() == nil {
+		dialog.ShowInformation("No Package", "Please load a ZIP package first", a.Window)
+		return
+	}
+
+	reviewer := widget.NewEntry()
+	reviewer.SetText(a.Settings.Get().Reviewer)
+	notes := widget.NewMultiLineEntry()
+	items := []*widget.FormItem{
+		widget.NewFormItem("Reviewer", reviewer),
+		widget.NewFormItem("Notes", notes),
+	}
+	dialog.ShowForm("Reject Package", "Choose Folder", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			files, err := a.Session.Reject(uri.Path(), reviewer.Text, notes.Text)
+			if err != nil {
+				dialog.ShowError(err, a.Window)
+				return
+			}
+			dialog.ShowInformation("Feedback Written", strconv.Itoa(len(files))+" files written to "+uri.Path(), a.Window)
+		}, a.Window)
+	}, a.Window)
+}
+
+func (a *AuditorApp) checkRepository() {
+	mgr, err := a.Session.Repo()
+	if err != nil {
+		dialog.ShowInformation("No Repository", "Please open a repository first", a.Window)
+		return
+	}
+	problems := mgr.CheckConsistency()
+	if len(problems) == 0 {
+		dialog.ShowInformation("Repository Check", "No problems found", a.Window)
+		return
+	}
+	dialog.ShowInformation("Repository Check", strings.Join(problems, "\n"), a.Window)
+}
+
+// writeAnalytics writes the repository overview into a chosen folder.
+func (a *AuditorApp) writeAnalytics() {
+	mgr, err := a.Session.Repo()
+	if err != nil {
+		dialog.ShowInformation("No Repository", "Please open a repository first", a.Window)
+		return
+	}
+	dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+		if err != nil || uri == nil {
+			return
+		}
+		analytics, err := report.NewAnalytics(mgr)
+		if err != nil {
+			dialog.ShowError(err, a.Window)
+			return
+		}
+		files, err := analytics.Write(uri.Path())
+		if err != nil {
+			dialog.ShowError(err, a.Window)
+			return
+		}
+		msg := strconv.Itoa(len(files)) + " files written to " + uri.Path()
+		if n := len(analytics.Unreadable); n > 0 {
+			msg += "\n" + strconv.Itoa(n) + " controllers could not be read; see the report"
+		}
+		dialog.ShowInformation("Analytics Written", msg, a.Window)
+	}, a.Window)
 }
 
 func (a *AuditorApp) Run() {
@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Sources of a package fact.
+const (
+	SourceIndex   = "index"
+	SourceVersion = "version"
+	SourceLayout  = "layout"
+)
+
+// Facts that a package repeats in more than one file.
+const (
+	FieldName        = "name"
+	FieldAuthor      = "author"
+	FieldDescription = "description"
+	FieldVersionName = "versionName"
+	FieldVersionCode = "versionCode"
+)
+
+// SourceValue is the value of a fact in one source file.
+type SourceValue struct {
+	Source string `json:"source"`
+	Value  string `json:"value"`
+}
+
+// Conflict is a fact whose sources disagree.
+type Conflict struct {
+	Field  string        `json:"field"`
+	Values []SourceValue `json:"values"`
+}
+
+// fact reads and writes one field in every source that carries it.
+type fact struct {
+	field string
+	get   func(p *ParsedPackage) []SourceValue
+	set   func(p *ParsedPackage, value string) error
+}
+
+var facts = []fact{
+	{
+		field: FieldName,
+		get: func(p *ParsedPackage) (v []SourceValue) {
+			if p.IndexEntry != nil {
+				v = append(v, SourceValue{SourceIndex, p.IndexEntry.Name})
+			}
+			if p.Layout != nil {
+				v = append(v, SourceValue{SourceLayout, p.Layout.Name})
+			}
+			return v
+		},
+		set: func(p *ParsedPackage, value string) error {
+			if p.IndexEntry != nil {
+				p.IndexEntry.Name = value
+			}
+			if p.Layout != nil {
+				p.Layout.Name = value
+			}
+			return nil
+		},
+	},
+	{
+		field: FieldAuthor,
+		get: func(p *ParsedPackage) (v []SourceValue) {
+			if p.VersionInfo != nil {
+				v = append(v, SourceValue{SourceVersion, p.VersionInfo.Author})
+			}
+			if p.Layout != nil {
+				v = append(v, SourceValue{SourceLayout, p.Layout.Author})
+			}
+			return v
+		},
+		set: func(p *ParsedPackage, value string) error {
+			if p.VersionInfo != nil {
+				p.VersionInfo.Author = value
+			}
+			if p.Layout != nil {
+				p.Layout.Author = value
+			}
+			return nil
+		},
+	},
+	{
+		field: FieldDescription,
+		get: func(p *ParsedPackage) (v []SourceValue) {
+			if p.VersionInfo != nil {
+				v = append(v, SourceValue{SourceVersion, p.VersionInfo.Description})
+			}
+			if p.Layout != nil {
+				v = append(v, SourceValue{SourceLayout, p.Layout.Description})
+			}
+			return v
+		},
+		set: func(p *ParsedPackage, value string) error {
+			if p.VersionInfo != nil {
+				p.VersionInfo.Description = value
+			}
+			if p.Layout != nil {
+				p.Layout.Description = value
+			}
+			return nil
+		},
+	},
+	{
+		field: FieldVersionName,
+		get: func(p *ParsedPackage) (v []SourceValue) {
+			if p.VersionInfo != nil {
+				v = append(v, SourceValue{SourceVersion, p.VersionInfo.Latest.VersionName})
+			}
+			if p.Layout != nil {
+				v = append(v, SourceValue{SourceLayout, p.Layout.Version})
+			}
+			return v
+		},
+		set: func(p *ParsedPackage, value string) error {
+			if p.VersionInfo != nil {
+				p.VersionInfo.Latest.VersionName = value
+			}
+			if p.Layout != nil {
+				p.Layout.Version = value
+			}
+			return nil
+		},
+	},
+	{
+		field: FieldVersionCode,
+		get: func(p *ParsedPackage) (v []SourceValue) {
+			if p.VersionInfo != nil {
+				v = append(v, SourceValue{SourceVersion, strconv.Itoa(p.VersionInfo.Latest.VersionCode)})
+			}
+			if p.Layout != nil {
+				v = append(v, SourceValue{SourceLayout, strconv.Itoa(p.Layout.VersionCode)})
+			}
+			return v
+		},
+		set: func(p *ParsedPackage, value string) error {
+			code, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid versionCode %q", value)
+			}
+			if p.VersionInfo != nil {
+				p.VersionInfo.Latest.VersionCode = code
+			}
+			if p.Layout != nil {
+				p.Layout.VersionCode = code
+			}
+			p.VersionCode = code
+			return nil
+		},
+	},
+}
+
+// Conflicts returns every fact whose sources disagree, in a fixed order.
+func (p *ParsedPackage) Conflicts() []Conflict {
+	conflicts := []Conflict{}
+	for _, f := range facts {
+		values := f.get(p)
+		for _, v := range values[min(1, len(values)):] {
+			if v.Value != values[0].Value {
+				conflicts = append(conflicts, Conflict{Field: f.field, Values: values})
+				break
+			}
+		}
+	}
+	return conflicts
+}
+
+// Resolve makes source the winner for field, copying its value into every
+// other source of the fact.
+func (p *ParsedPackage) Resolve(field, source string) error {
+	for _, f := range facts {
+		if f.field != field {
+			continue
+		}
+		for _, v := range f.get(p) {
+			if v.Source == source {
+				return f.set(p, v.Value)
+			}
+		}
+		return fmt.Errorf("package has no %s for %s", source, field)
+	}
+	return fmt.Errorf("unknown field %q", field)
+}
@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+// consistentPackage returns a package whose folder, index.json, version.json
+// and layout all agree.
+func consistentPackage() *ParsedPackage {
+	return &ParsedPackage{
+		ControllerID: "demo",
+		VersionCode:  3,
+		IndexEntry:   &models.IndexEntry{ID: "demo", Name: "Demo", Device: []models.Device{models.DevicePhone}},
+		VersionInfo: &models.RepoVersion{
+			Author:      "alice",
+			Description: "A demo",
+			Latest:      models.Version{VersionCode: 3, VersionName: "1.2"},
+		},
+		Layout: &models.ControllerLayout{
+			ID: "demo", Name: "Demo", Author: "alice", Description: "A demo", Version: "1.2", VersionCode: 3,
+		},
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(p *ParsedPackage)
+		want   []Conflict
+	}{
+		{"consistent", func(p *ParsedPackage) {}, []Conflict{}},
+		{"name", func(p *ParsedPackage) { p.Layout.Name = "Other" }, []Conflict{
+			{FieldName, []SourceValue{{SourceIndex, "Demo"}, {SourceLayout, "Other"}}},
+		}},
+		{"author", func(p *ParsedPackage) { p.VersionInfo.Author = "bob" }, []Conflict{
+			{FieldAuthor, []SourceValue{{SourceVersion, "bob"}, {SourceLayout, "alice"}}},
+		}},
+		{"description", func(p *ParsedPackage) { p.Layout.Description = "" }, []Conflict{
+			{FieldDescription, []SourceValue{{SourceVersion, "A demo"}, {SourceLayout, ""}}},
+		}},
+		{"version name and code", func(p *ParsedPackage) {
+			p.Layout.Version = "1.3"
+			p.Layout.VersionCode = 4
+		}, []Conflict{
+			{FieldVersionName, []SourceValue{{SourceVersion, "1.2"}, {SourceLayout, "1.3"}}},
+			{FieldVersionCode, []SourceValue{{SourceVersion, "3"}, {SourceLayout, "4"}}},
+		}},
+		// IDs and devices are not facts: a mismatched ID is refused by the
+		// package.id-mismatch rule, and only index.json lists devices.
+		{"ID and device", func(p *ParsedPackage) {
+			p.IndexEntry.ID = "other"
+			p.Layout.ID = "third"
+			p.IndexEntry.Device = []models.Device{models.DeviceTablet}
+		}, []Conflict{}},
+		{"single source", func(p *ParsedPackage) {
+			p.Layout = nil
+			p.VersionInfo.Author = "bob"
+		}, []Conflict{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg := consistentPackage()
+			tt.change(pkg)
+			if got := pkg.Conflicts(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Conflicts() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		field, source string
+		change        func(p *ParsedPackage)
+		check         func(p *ParsedPackage) bool
+	}{
+		{FieldName, SourceLayout,
+			func(p *ParsedPackage) { p.Layout.Name = "Other" },
+			func(p *ParsedPackage) bool { return p.IndexEntry.Name == "Other" && p.Layout.Name == "Other" }},
+		{FieldName, SourceIndex,
+			func(p *ParsedPackage) { p.Layout.Name = "Other" },
+			func(p *ParsedPackage) bool { return p.IndexEntry.Name == "Demo" && p.Layout.Name == "Demo" }},
+		{FieldAuthor, SourceVersion,
+			func(p *ParsedPackage) { p.Layout.Author = "bob" },
+			func(p *ParsedPackage) bool { return p.Layout.Author == "alice" }},
+		{FieldDescription, SourceLayout,
+			func(p *ParsedPackage) { p.Layout.Description = "Better" },
+			func(p *ParsedPackage) bool { return p.VersionInfo.Description == "Better" }},
+		{FieldVersionName, SourceLayout,
+			func(p *ParsedPackage) { p.Layout.Version = "1.3" },
+			func(p *ParsedPackage) bool { return p.VersionInfo.Latest.VersionName == "1.3" }},
+		{FieldVersionCode, SourceLayout,
+			func(p *ParsedPackage) { p.Layout.VersionCode = 4 },
+			func(p *ParsedPackage) bool { return p.VersionInfo.Latest.VersionCode == 4 && p.VersionCode == 4 }},
+		{FieldVersionCode, SourceVersion,
+			func(p *ParsedPackage) { p.Layout.VersionCode = 4 },
+			func(p *ParsedPackage) bool { return p.Layout.VersionCode == 3 && p.VersionCode == 3 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field+"/"+tt.source, func(t *testing.T) {
+			pkg := consistentPackage()
+			tt.change(pkg)
+			if err := pkg.Resolve(tt.field, tt.source); err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(pkg) {
+				t.Errorf("%s was not taken from %s", tt.field, tt.source)
+			}
+			if c := pkg.Conflicts(); len(c) != 0 {
+				t.Errorf("conflicts left after Resolve: %+v", c)
+			}
+		})
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		field, source string
+		change        func(p *ParsedPackage)
+	}{
+		{"unknown field", "id", SourceLayout, func(p *ParsedPackage) {}},
+		{"source without the field", FieldName, SourceVersion, func(p *ParsedPackage) {}},
+		{"missing source", FieldAuthor, SourceLayout, func(p *ParsedPackage) { p.Layout = nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg := consistentPackage()
+			tt.change(pkg)
+			if err := pkg.Resolve(tt.field, tt.source); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
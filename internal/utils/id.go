@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Controller ID length limits.
+const (
+	ControllerIDMinLen = 3
+	ControllerIDMaxLen = 64
+)
+
+// reservedIDs cannot be used as controller IDs: Windows device names and
+// the names of the repository's own files and directories.
+var reservedIDs = map[string]bool{
+	"con": true, "prn": true, "aux": true, "nul": true,
+	"com1": true, "com2": true, "com3": true, "com4": true, "com5": true,
+	"com6": true, "com7": true, "com8": true, "com9": true,
+	"lpt1": true, "lpt2": true, "lpt3": true, "lpt4": true, "lpt5": true,
+	"lpt6": true, "lpt7": true, "lpt8": true, "lpt9": true,
+	"index": true, "category": true, "repo_json": true, "versions": true, "screenshots": true,
+}
+
+// ValidateControllerID checks id against the naming policy. IDs name a
+// directory under repo_json, so they may only contain ASCII letters, digits,
+// '-' and '_', must start with a letter or digit and must not be reserved.
+func ValidateControllerID(id string) error {
+	if len(id) < ControllerIDMinLen || len(id) > ControllerIDMaxLen {
+		return fmt.Errorf("controller ID %q must be %d to %d characters long", id, ControllerIDMinLen, ControllerIDMaxLen)
+	}
+	for i, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case (r == '-' || r == '_') && i > 0:
+		default:
+			return fmt.Errorf("controller ID %q contains %q; only letters, digits, '-' and '_' are allowed and it must start with a letter or digit", id, r)
+		}
+	}
+	if reservedIDs[strings.ToLower(id)] {
+		return fmt.Errorf("controller ID %q is reserved", id)
+	}
+	return nil
+}
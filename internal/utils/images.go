@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"image"
+	"os"
+	"time"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/imaging"
+)
+
+// decodedImage is the result of decoding an image file, together with the
+// size and modification time the file had at the time.
+type decodedImage struct {
+	modTime time.Time
+	size    int64
+	info    *imaging.Info
+	img     image.Image
+	err     error
+}
+
+// Image decodes the image at path, normally the package's icon or one of its
+// screenshots. Each file is decoded once per package; the result is reused
+// until the file changes on disk.
+func (p *ParsedPackage) Image(path string) (*imaging.Info, image.Image, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if d, ok := p.images[path]; ok && d.modTime.Equal(st.ModTime()) && d.size == st.Size() {
+		return d.info, d.img, d.err
+	}
+	info, img, err := imaging.Inspect(path)
+	if p.images == nil {
+		p.images = make(map[string]decodedImage)
+	}
+	p.images[path] = decodedImage{modTime: st.ModTime(), size: st.Size(), info: info, img: img, err: err}
+	return info, img, err
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/imaging"
+)
+
+func writeTestPNG(t *testing.T, path string, w, h int) {
+	t.Helper()
+	if err := imaging.WritePNG(path, image.NewNRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImageIsDecodedOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "icon.png")
+	writeTestPNG(t, path, 2, 2)
+	pkg := &ParsedPackage{}
+
+	info, first, err := pkg.Image(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Width != 2 {
+		t.Fatalf("width = %d, want 2", info.Width)
+	}
+	if _, again, _ := pkg.Image(path); again != first {
+		t.Error("unchanged file was decoded again")
+	}
+
+	writeTestPNG(t, path, 3, 3)
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if info, _, err := pkg.Image(path); err != nil || info.Width != 3 {
+		t.Errorf("after rewrite: info = %+v, err = %v, want width 3", info, err)
+	}
+}
+
+func TestImageReportsDecodeErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "1.png")
+	if err := os.WriteFile(path, []byte("not an image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pkg := &ParsedPackage{}
+	for range 2 {
+		if _, _, err := pkg.Image(path); err == nil {
+			t.Fatal("expected a decode error")
+		}
+	}
+	if _, _, err := pkg.Image(filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
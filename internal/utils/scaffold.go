@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+// DefaultLang is the primary locale given to scaffolded index entries.
+const DefaultLang = "zh"
+
+// NewPackageFromLayout builds a package from a bare layout JSON as exported
+// by FCL. The index entry and version info are pre-filled from the layout;
+// the icon and screenshots are added afterwards with SetIcon and
+// AddScreenshots.
+func NewPackageFromLayout(layoutPath string) (*ParsedPackage, error) {
+	data, err := os.ReadFile(layoutPath)
+	if err != nil {
+		return nil, err
+	}
+	var layout models.ControllerLayout
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return nil, fmt.Errorf("invalid layout JSON: %v", err)
+	}
+	if layout.ID == "" {
+		return nil, fmt.Errorf("layout has no id")
+	}
+
+	pkg := &ParsedPackage{
+		ControllerID: layout.ID,
+		VersionCode:  layout.VersionCode,
+		Layout:       &layout,
+		IndexEntry: &models.IndexEntry{
+			ID:         layout.ID,
+			Lang:       DefaultLang,
+			Name:       layout.Name,
+			Device:     []models.Device{},
+			Categories: []int{},
+		},
+		VersionInfo: &models.RepoVersion{
+			Author:      layout.Author,
+			Description: layout.Description,
+			Latest: models.Version{
+				VersionCode: layout.VersionCode,
+				VersionName: layout.Version,
+			},
+			History: []models.Version{},
+		},
+		SourcePath: layoutPath,
+	}
+	if sum, err := FileSHA256(layoutPath); err == nil {
+		pkg.SHA256 = sum
+	}
+	return pkg, nil
+}
+
+// SetIcon uses a copy of the image at path as the package icon.
+func (p *ParsedPackage) SetIcon(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	icon, err := p.importFile(path, "icon")
+	if err != nil {
+		return err
+	}
+	p.IconPath = icon
+	return nil
+}
+
+// AddScreenshots appends copies of images to the package's screenshots.
+func (p *ParsedPackage) AddScreenshots(paths ...string) error {
+	for _, path := range paths {
+		if !IsScreenshotFile(path) {
+			return fmt.Errorf("%s is not a PNG or JPEG file", path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
+	}
+	for _, path := range paths {
+		shot, err := p.importFile(path, "screenshot")
+		if err != nil {
+			return err
+		}
+		p.Screenshots = append(p.Screenshots, shot)
+	}
+	if p.VersionInfo != nil {
+		p.VersionInfo.Screenshot = len(p.Screenshots)
+	}
+	return nil
+}
+
+// importFile copies the file at path into the package's TempDir, creating
+// one if the package has none yet, and returns the path of the copy. Every
+// copy gets a new name, so earlier edits keep their files for undo.
+func (p *ParsedPackage) importFile(path, prefix string) (string, error) {
+	if p.TempDir == "" {
+		dir, err := os.MkdirTemp("", "fcl-auditor-*")
+		if err != nil {
+			return "", err
+		}
+		p.TempDir = dir
+	}
+	in, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	out, err := os.CreateTemp(p.TempDir, prefix+"-*"+strings.ToLower(filepath.Ext(path)))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return "", err
+	}
+	return out.Name(), out.Close()
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/models"
+)
+
+func writeTestLayout(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "layout.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewPackageFromLayout(t *testing.T) {
+	path := writeTestLayout(t, `{"id": "demo_pad", "name": "Demo Pad", "version": "1.2", "versionCode": 3,
+		"author": "alice", "description": "A demo"}`)
+	pkg, err := NewPackageFromLayout(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.ControllerID != "demo_pad" || pkg.VersionCode != 3 || pkg.SourcePath != path || pkg.SHA256 == "" {
+		t.Errorf("package = %+v", pkg)
+	}
+	wantEntry := models.IndexEntry{
+		ID:         "demo_pad",
+		Lang:       DefaultLang,
+		Name:       "Demo Pad",
+		Device:     []models.Device{},
+		Categories: []int{},
+	}
+	if !reflect.DeepEqual(*pkg.IndexEntry, wantEntry) {
+		t.Errorf("index entry = %+v, want %+v", *pkg.IndexEntry, wantEntry)
+	}
+	wantVersion := models.RepoVersion{
+		Author:      "alice",
+		Description: "A demo",
+		Latest:      models.Version{VersionCode: 3, VersionName: "1.2"},
+		History:     []models.Version{},
+	}
+	if !reflect.DeepEqual(*pkg.VersionInfo, wantVersion) {
+		t.Errorf("version info = %+v, want %+v", *pkg.VersionInfo, wantVersion)
+	}
+	// The scaffold starts out consistent with its layout.
+	if c := pkg.Conflicts(); len(c) != 0 {
+		t.Errorf("conflicts = %+v", c)
+	}
+	if pkg.IconPath != "" || len(pkg.Screenshots) != 0 || pkg.TempDir != "" {
+		t.Errorf("images were added: icon %q, screenshots %v", pkg.IconPath, pkg.Screenshots)
+	}
+}
+
+func TestNewPackageFromLayoutErrors(t *testing.T) {
+	tests := []struct{ name, content string }{
+		{"empty id", `{"id": "", "name": "Demo"}`},
+		{"missing id", `{"name": "Demo"}`},
+		{"invalid JSON", `{"id": "demo"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPackageFromLayout(writeTestLayout(t, tt.content)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+	if _, err := NewPackageFromLayout(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestPickedImagesAreCopiedIntoTempDir(t *testing.T) {
+	src := t.TempDir()
+	icon := filepath.Join(src, "Icon.PNG")
+	shot := filepath.Join(src, "shot.png")
+	writeTestPNG(t, icon, 64, 64)
+	writeTestPNG(t, shot, 16, 9)
+
+	pkg := &ParsedPackage{}
+	defer pkg.Cleanup()
+	if err := pkg.SetIcon(icon); err != nil {
+		t.Fatal(err)
+	}
+	first := pkg.IconPath
+	if err := pkg.SetIcon(icon); err != nil {
+		t.Fatal(err)
+	}
+	if err := pkg.AddScreenshots(shot); err != nil {
+		t.Fatal(err)
+	}
+	if pkg.TempDir == "" {
+		t.Fatal("no temp dir was created")
+	}
+	for _, path := range []string{first, pkg.IconPath, pkg.Screenshots[0]} {
+		if filepath.Dir(path) != pkg.TempDir {
+			t.Errorf("%s is not in the package temp dir %s", path, pkg.TempDir)
+		}
+		if !IsScreenshotFile(path) {
+			t.Errorf("%s lost its image extension", path)
+		}
+	}
+	if first == pkg.IconPath {
+		t.Error("setting the icon again overwrote the earlier copy")
+	}
+	if err := pkg.AddScreenshots(filepath.Join(src, "notes.txt")); err == nil {
+		t.Error("expected an error for a non-image file")
+	}
+}
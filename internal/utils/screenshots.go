@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var screenshotExts = map[string]bool{".png": true, ".jpg": true, ".jpeg": true}
+
+// IsScreenshotFile reports whether name has a screenshot image extension,
+// ignoring case.
+func IsScreenshotFile(name string) bool {
+	return screenshotExts[strings.ToLower(filepath.Ext(name))]
+}
+
+// ListScreenshots returns the screenshot images in dir. Numbered files come
+// first in numeric order, so 2.png sorts before 10.png.
+func ListScreenshots(dir string) []string {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, f := range files {
+		if !f.IsDir() && IsScreenshotFile(f.Name()) {
+			names = append(names, f.Name())
+		}
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		ni, erri := strconv.Atoi(strings.TrimSuffix(names[i], filepath.Ext(names[i])))
+		nj, errj := strconv.Atoi(strings.TrimSuffix(names[j], filepath.Ext(names[j])))
+		switch {
+		case erri == nil && errj == nil:
+			return ni < nj
+		case erri == nil || errj == nil:
+			return erri == nil
+		}
+		return names[i] < names[j]
+	})
+
+	paths := make([]string, len(names))
+	for i, n := range names {
+		paths[i] = filepath.Join(dir, n)
+	}
+	return paths
+}
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -23,8 +25,14 @@ type ParsedPackage struct {
 	TempDir      string
 	IsUpdate     bool
 	CurrentIndex *models.IndexEntry
+	SourcePath   string
+	SHA256       string
+
+	images map[string]decodedImage // see Image
 }
 
+// ParseControllerZip extracts a submission ZIP into a new temp dir and loads
+// the package from it. The temp dir is removed again if parsing fails.
 func ParseControllerZip(zipPath string) (*ParsedPackage, error) {
 	r, err := zip.OpenReader(zipPath)
 	if err != nil {
@@ -36,15 +44,30 @@ func ParseControllerZip(zipPath string) (*ParsedPackage, error) {
 	if err != nil {
 		return nil, err
 	}
+	pkg, err := parseControllerZip(r, zipPath, tempDir)
+	if err != nil {
+		os.RemoveAll(tempDir)
+		return nil, err
+	}
+	return pkg, nil
+}
 
+func parseControllerZip(r *zip.ReadCloser, zipPath, tempDir string) (*ParsedPackage, error) {
 	pkg := &ParsedPackage{
-		TempDir: tempDir,
+		TempDir:    tempDir,
+		SourcePath: zipPath,
+	}
+	if sum, err := FileSHA256(zipPath); err == nil {
+		pkg.SHA256 = sum
 	}
 
 	var controllerID string
 
 	// First pass: find controller ID and extract files
 	for _, f := range r.File {
+		if !filepath.IsLocal(f.Name) {
+			return nil, fmt.Errorf("zip entry %q escapes the package directory", f.Name)
+		}
 		parts := strings.Split(filepath.ToSlash(f.Name), "/")
 		if len(parts) < 2 {
 			continue
@@ -134,18 +157,26 @@ func ParseControllerZip(zipPath string) (*ParsedPackage, error) {
 		pkg.IconPath = ""
 	}
 
-	screenshotDir := filepath.Join(basePath, "screenshots")
-	if files, err := os.ReadDir(screenshotDir); err == nil {
-		for _, f := range files {
-			if !f.IsDir() && (strings.HasSuffix(f.Name(), ".png") || strings.HasSuffix(f.Name(), ".jpg")) {
-				pkg.Screenshots = append(pkg.Screenshots, filepath.Join(screenshotDir, f.Name()))
-			}
-		}
-	}
+	pkg.Screenshots = ListScreenshots(filepath.Join(basePath, "screenshots"))
 
 	return pkg, nil
 }
 
+// FileSHA256 returns the hex encoded SHA-256 of a file's contents.
+func FileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func (p *ParsedPackage) Cleanup() {
 	os.RemoveAll(p.TempDir)
 }
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "package.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseControllerZipRemovesTempDirOnError(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"escaping entry", map[string]string{"demo/index.json": "{}", "../evil.json": "{}"}},
+		{"two controllers", map[string]string{"demo/index.json": "{}", "other/index.json": "{}"}},
+		{"no controller", map[string]string{"readme.txt": "hi"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zipPath := writeTestZip(t, tt.files)
+			tmp := t.TempDir()
+			t.Setenv("TMPDIR", tmp)
+			if _, err := ParseControllerZip(zipPath); err == nil {
+				t.Fatal("expected an error")
+			}
+			entries, err := os.ReadDir(tmp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("temp dir left behind: %v", entries)
+			}
+		})
+	}
+}
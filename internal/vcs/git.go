@@ -0,0 +1,179 @@
+package vcs
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Git runs the git binary against a working tree.
+type Git struct {
+	Dir string
+}
+
+// Commit is one entry of git log output.
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+}
+
+// Open returns a Git for dir, failing if git is not installed or dir is not
+// inside a work tree.
+func Open(dir string) (*Git, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git executable not found: %v", err)
+	}
+	g := &Git{Dir: dir}
+	out, err := g.run("rev-parse", "--is-inside-work-tree")
+	if err != nil || strings.TrimSpace(out) != "true" {
+		return nil, fmt.Errorf("%s is not a git working tree", dir)
+	}
+	return g, nil
+}
+
+// Status returns the porcelain status lines, limited to pathspecs when any
+// are given; an empty result means those paths are clean.
+func (g *Git) Status(pathspecs ...string) ([]string, error) {
+	args := []string{"status", "--porcelain"}
+	if len(pathspecs) > 0 {
+		args = append(append(args, "--"), pathspecs...)
+	}
+	out, err := g.run(args...)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, l := range strings.Split(out, "\n") {
+		if strings.TrimSpace(l) != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines, nil
+}
+
+// CurrentBranch returns the checked out branch name.
+func (g *Git) CurrentBranch() (string, error) {
+	out, err := g.run("rev-parse", "--abbrev-ref", "HEAD")
+	return strings.TrimSpace(out), err
+}
+
+// Head returns the checked out branch, or the commit hash when HEAD is
+// detached.
+func (g *Git) Head() (string, error) {
+	branch, err := g.CurrentBranch()
+	if err != nil || branch != "HEAD" {
+		return branch, err
+	}
+	out, err := g.run("rev-parse", "HEAD")
+	return strings.TrimSpace(out), err
+}
+
+// BranchExists reports whether a local branch called name exists.
+func (g *Git) BranchExists(name string) bool {
+	_, err := g.run("rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+	return err == nil
+}
+
+// CreateBranch creates and checks out a new branch from HEAD.
+func (g *Git) CreateBranch(name string) error {
+	_, err := g.run("checkout", "-b", name)
+	return err
+}
+
+// Checkout switches to a branch or commit.
+func (g *Git) Checkout(ref string) error {
+	_, err := g.run("checkout", ref)
+	return err
+}
+
+// DeleteBranch force-deletes a local branch.
+func (g *Git) DeleteBranch(name string) error {
+	_, err := g.run("branch", "-D", name)
+	return err
+}
+
+// Commit stages the changes under paths, and nothing else, and commits them.
+func (g *Git) Commit(message string, paths ...string) error {
+	if _, err := g.run(append([]string{"add", "-A", "--"}, paths...)...); err != nil {
+		return err
+	}
+	_, err := g.run("commit", "-m", message)
+	return err
+}
+
+// Discard puts paths back the way they are in HEAD: tracked files are
+// restored and unstaged, untracked files under them are deleted.
+func (g *Git) Discard(paths ...string) error {
+	if _, err := g.run(append([]string{"reset", "-q", "--"}, paths...)...); err != nil {
+		return err
+	}
+	out, err := g.run(append([]string{"ls-tree", "-r", "-z", "--name-only", "HEAD", "--"}, paths...)...)
+	if err != nil {
+		return err
+	}
+	var tracked []string
+	for _, name := range strings.Split(out, "\x00") {
+		if name != "" {
+			tracked = append(tracked, name)
+		}
+	}
+	if len(tracked) > 0 {
+		if _, err := g.run(append([]string{"checkout", "HEAD", "--"}, tracked...)...); err != nil {
+			return err
+		}
+	}
+	_, err = g.run(append([]string{"clean", "-fdq", "--"}, paths...)...)
+	return err
+}
+
+// Log returns the commits touching path, newest first.
+func (g *Git) Log(path string) ([]Commit, error) {
+	out, err := g.run("log", "--format=%H%x1f%an%x1f%aI%x1f%s", "--", path)
+	if err != nil {
+		return nil, err
+	}
+	commits := []Commit{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[2])
+		commits = append(commits, Commit{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Date:    date,
+			Subject: fields[3],
+		})
+	}
+	return commits, nil
+}
+
+// LastCommitDate returns the committer date of the newest commit touching
+// path, or the zero time if there is none.
+func (g *Git) LastCommitDate(path string) (time.Time, error) {
+	out, err := g.run("log", "-1", "--format=%cI", "--", path)
+	if err != nil || strings.TrimSpace(out) == "" {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, strings.TrimSpace(out))
+}
+
+func (g *Git) run(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", g.Dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,178 @@
+package vcs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestGit creates a repository with one commit of a.txt on main.
+func newTestGit(t *testing.T) *Git {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "tester")
+	t.Setenv("GIT_AUTHOR_EMAIL", "tester@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "tester")
+	t.Setenv("GIT_COMMITTER_EMAIL", "tester@example.com")
+	g := &Git{Dir: t.TempDir()}
+	if _, err := g.run("init", "-q", "-b", "main"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, g, "a.txt", "one")
+	if err := g.Commit("initial", "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func writeFile(t *testing.T, g *Git, name, content string) {
+	t.Helper()
+	path := filepath.Join(g.Dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, g *Git, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(g.Dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestOpen(t *testing.T) {
+	g := newTestGit(t)
+	if _, err := Open(g.Dir); err != nil {
+		t.Errorf("Open(work tree): %v", err)
+	}
+	if _, err := Open(t.TempDir()); err == nil {
+		t.Error("Open succeeded outside a work tree")
+	}
+}
+
+func TestCommitStagesOnlyPaths(t *testing.T) {
+	g := newTestGit(t)
+	writeFile(t, g, "a.txt", "two")
+	writeFile(t, g, "b.txt", "other")
+	if err := g.Commit("change a", "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	status, err := g.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status) != 1 || !strings.HasSuffix(status[0], "b.txt") {
+		t.Errorf("status = %q, want only b.txt", status)
+	}
+	if status, _ := g.Status("a.txt"); len(status) != 0 {
+		t.Errorf("a.txt status = %q, want clean", status)
+	}
+
+	log, err := g.Log("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log) != 2 || log[0].Subject != "change a" || log[1].Subject != "initial" || log[0].Author != "tester" {
+		t.Errorf("log = %+v", log)
+	}
+	date, err := g.LastCommitDate("a.txt")
+	if err != nil || time.Since(date) > time.Hour {
+		t.Errorf("LastCommitDate = %v, %v", date, err)
+	}
+	if date, err := g.LastCommitDate("b.txt"); err != nil || !date.IsZero() {
+		t.Errorf("LastCommitDate of an uncommitted file = %v, %v", date, err)
+	}
+}
+
+func TestBranches(t *testing.T) {
+	g := newTestGit(t)
+	if head, err := g.Head(); err != nil || head != "main" {
+		t.Fatalf("Head() = %q, %v", head, err)
+	}
+	if err := g.CreateBranch("side"); err != nil {
+		t.Fatal(err)
+	}
+	if !g.BranchExists("side") || g.BranchExists("missing") {
+		t.Error("BranchExists is wrong")
+	}
+	if head, _ := g.Head(); head != "side" {
+		t.Errorf("Head() after CreateBranch = %q", head)
+	}
+	if err := g.CreateBranch("side"); err == nil {
+		t.Error("creating an existing branch succeeded")
+	}
+	writeFile(t, g, "a.txt", "side")
+	if err := g.Commit("on side", "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Checkout("main"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, g, "a.txt"); got != "one" {
+		t.Errorf("a.txt on main = %q", got)
+	}
+	if err := g.DeleteBranch("side"); err != nil {
+		t.Fatal(err)
+	}
+	if g.BranchExists("side") {
+		t.Error("side still exists")
+	}
+
+	hash, err := g.run("rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Checkout(strings.TrimSpace(hash)); err != nil {
+		t.Fatal(err)
+	}
+	if head, _ := g.Head(); head != strings.TrimSpace(hash) {
+		t.Errorf("detached Head() = %q, want %s", head, hash)
+	}
+}
+
+func TestDiscard(t *testing.T) {
+	g := newTestGit(t)
+	writeFile(t, g, "dir/tracked.txt", "kept")
+	if err := g.Commit("add dir", "dir"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, g, "dir/tracked.txt", "edited")
+	writeFile(t, g, "dir/new.txt", "untracked")
+	writeFile(t, g, "a.txt", "staged")
+	if _, err := g.run("add", "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, g, "outside.txt", "left alone")
+
+	if err := g.Discard("dir", "a.txt", "never-existed"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, g, "dir/tracked.txt"); got != "kept" {
+		t.Errorf("dir/tracked.txt = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(g.Dir, "dir", "new.txt")); !os.IsNotExist(err) {
+		t.Errorf("dir/new.txt was not removed: %v", err)
+	}
+	if got := readFile(t, g, "a.txt"); got != "one" {
+		t.Errorf("a.txt = %q", got)
+	}
+	status, err := g.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status) != 1 || !strings.HasSuffix(status[0], "outside.txt") {
+		t.Errorf("status = %q, want only outside.txt", status)
+	}
+}
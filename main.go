@@ -2,7 +2,13 @@ package main
 
 import (
 	"embed"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
 
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/thumbnail"
+	"github.com/tungsten-fcl/fcl-controller-auditor/internal/ui"
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
@@ -12,19 +18,46 @@ import (
 var assets embed.FS
 
 func main() {
+	frontend := flag.String("ui", "wails", "user interface to start: wails or fyne")
+	repo := flag.String("repo", "", "repository root to open on startup (fyne only)")
+	flag.Parse()
+
+	switch *frontend {
+	case "wails":
+		runWails()
+	case "fyne":
+		auditor, err := ui.NewAuditorApp(*repo)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		auditor.Run()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown -ui %q (want wails or fyne)\n", *frontend)
+		os.Exit(2)
+	}
+}
+
+func runWails() {
 	// Create an instance of the app structure
 	app := NewApp()
 
+	// Requests not matching an embedded asset fall through to this handler
+	handler := http.NewServeMux()
+	handler.Handle(thumbnail.Route, app.thumbs)
+
 	// Create application with options
 	err := wails.Run(&options.App{
 		Title:  "FCL-Controller-Auditor-Wails",
 		Width:  1024,
 		Height: 768,
 		AssetServer: &assetserver.Options{
-			Assets: assets,
+			Assets:  assets,
+			Handler: handler,
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        app.startup,
+		OnShutdown:       app.shutdown,
 		Bind: []interface{}{
 			app,
 		},